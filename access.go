@@ -0,0 +1,92 @@
+package player
+
+import (
+	"context"
+	"time"
+)
+
+// Access is record of segment served to client.
+type Access struct {
+	Key      string // stream key from Config
+	ID       int64  // segment id
+	Client   string // client from WithClient, empty if not set
+	Bytes    int
+	Duration time.Duration
+	Err      error
+}
+
+type clientKey struct{}
+
+// WithClient returns ctx that identifies client in Access records, e.g.
+// by remote address. Use it with Cursor.ReadNextContext.
+func WithClient(ctx context.Context, client string) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// servingOps are traced operations that serve segments to clients.
+var servingOps = map[string]bool{
+	"ReadID":          true,
+	"Cursor.ReadNext": true,
+	"WriteSegmentTo":  true,
+	"Get":             true,
+	"GetLatest":       true,
+	"GetFrom":         true,
+	"GetGen":          true,
+	"ReadLast":        true,
+	"SeekableReader":  true,
+}
+
+// AccessLog returns Tracer that calls f for every segment served by
+// Buffer, e.g. by Cursor, ReadID or WriteSegmentTo, and passes all spans
+// to next, if not nil. Durations are measured by clock, which should be
+// Clock of Buffer; system clock is used if nil.
+func AccessLog(f func(Access), next Tracer, clock Clock) Tracer {
+	if next == nil {
+		next = noopTracer{}
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return accessTracer{f: f, next: next, clock: clock}
+}
+
+type accessTracer struct {
+	f     func(Access)
+	next  Tracer
+	clock Clock
+}
+
+func (t accessTracer) Start(ctx context.Context, info SpanInfo) Span {
+	span := t.next.Start(ctx, info)
+	if !servingOps[info.Op] {
+		return span
+	}
+	client, _ := ctx.Value(clientKey{}).(string)
+	return &accessSpan{
+		t:      t,
+		span:   span,
+		start:  t.clock.Now(),
+		info:   info,
+		client: client,
+	}
+}
+
+type accessSpan struct {
+	t      accessTracer
+	span   Span
+	start  time.Time
+	info   SpanInfo
+	client string
+}
+
+func (s *accessSpan) End(n int, err error) {
+	s.span.End(n, err)
+	s.t.f(Access{
+		Key:      s.info.Key,
+		ID:       s.info.ID,
+		Client:   s.client,
+		Bytes:    n,
+		Duration: s.t.clock.Now().Sub(s.start),
+		Err:      err,
+	})
+}
@@ -0,0 +1,159 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestAccessLog(t *testing.T) {
+	var records []Access
+	traced := new(testTracer)
+	b := New(Config{
+		Key:     "live",
+		Count:   2,
+		Segment: 4,
+		Tracer: AccessLog(func(a Access) {
+			records = append(records, a)
+		}, traced, nil),
+	})
+	if _, err := b.Write([]byte{1, 1, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	c := b.NewCursor(0)
+	ctx := WithClient(context.Background(), "10.0.0.1")
+	if _, err := c.ReadNextContext(ctx, new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ReadID(new(bytes.Buffer), 5); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if len(traced.spans) != 3 {
+		t.Error("spans should be passed to next tracer", len(traced.spans))
+	}
+	if len(records) != 2 {
+		t.Fatal("bad records", records)
+	}
+	if r := records[0]; r.Key != "live" || r.ID != 0 || r.Client != "10.0.0.1" || r.Bytes != 4 || r.Err != nil {
+		t.Errorf("bad record %+v", r)
+	}
+	if r := records[1]; r.ID != 5 || r.Client != "" || errors.Cause(r.Err) != ErrMiss {
+		t.Errorf("bad record %+v", r)
+	}
+}
+
+// advancingWriter advances clock on every write.
+type advancingWriter struct {
+	c *FakeClock
+	d time.Duration
+}
+
+func (w advancingWriter) Write(p []byte) (int, error) {
+	w.c.Advance(w.d)
+	return len(p), nil
+}
+
+func TestAccessLog_Serving(t *testing.T) {
+	var records []Access
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{
+		Count:   4,
+		Segment: 2,
+		Clock:   c,
+		Tracer: AccessLog(func(a Access) {
+			records = append(records, a)
+		}, nil, c),
+	})
+	if _, err := b.Write([]byte{0, 0, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if err := b.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetLatest(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetFrom(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.GetGen(buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.WriteSegmentTo(io.Discard, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ReadLast(advancingWriter{c: c, d: time.Second}, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(b.SeekableReader()); err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		id       int64
+		duration time.Duration
+	}{
+		{0, 0}, {1, 0}, {0, 0}, {1, 0}, {1, 0},
+		{0, time.Second}, {1, time.Second},
+		{0, 0}, {1, 0},
+	}
+	if len(records) != len(want) {
+		t.Fatal("bad records", records)
+	}
+	for i, w := range want {
+		if r := records[i]; r.ID != w.id || r.Bytes != 2 || r.Duration != w.duration || r.Err != nil {
+			t.Errorf("bad record %d: %+v", i, r)
+		}
+	}
+}
+
+func TestAccessLog_Cursor(t *testing.T) {
+	var records []Access
+	clock := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{
+		Count:   2,
+		Segment: 2,
+		Clock:   clock,
+		Tracer: AccessLog(func(a Access) {
+			records = append(records, a)
+		}, nil, clock),
+	})
+	for i := byte(0); i < 4; i++ {
+		if _, err := b.Write([]byte{i, i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := b.NewCursor(0)
+	defer c.Close()
+	c.SetCatchUp(CatchUpSkip)
+	c.SetPace(time.Second)
+	if _, err := c.ReadNext(io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte{4, 4}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.ReadNext(io.Discard)
+		done <- err
+	}()
+	waitTimers(t, clock, 1)
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	// ids are resolved by catch-up, pacing is not serving time
+	if len(records) != 2 {
+		t.Fatal("bad records", records)
+	}
+	for i, id := range []int64{3, 4} {
+		if r := records[i]; r.ID != id || r.Duration != 0 {
+			t.Errorf("bad record %d: %+v", i, r)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package player
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Archive is cold tier of evicted segments, e.g. on disk or S3, usually
+// populated from OnSegment or OnEvict hook.
+type Archive interface {
+	// Segment returns stored data of segment with provided id.
+	Segment(id int64) ([]byte, error)
+}
+
+// archiveCall is in-flight fetch from Archive.
+type archiveCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// readback is small cache of segments fetched from Archive with
+// de-duplication of concurrent fetches of the same segment.
+type readback struct {
+	mux      sync.Mutex
+	max      int
+	entries  map[int64][]byte
+	order    []int64 // ids in order of caching, oldest first
+	inflight map[int64]*archiveCall
+}
+
+// fromArchive returns segment that was evicted from Buffer, fetching it
+// from Archive. Returned slice must not be modified. If segment is not
+// archived, miss is returned.
+func (b *Buffer) fromArchive(id int64, miss error) ([]byte, error) {
+	r := b.readback
+	r.mux.Lock()
+	if data, ok := r.entries[id]; ok {
+		r.mux.Unlock()
+		return data, nil
+	}
+	if call, ok := r.inflight[id]; ok {
+		r.mux.Unlock()
+		<-call.done
+		return call.data, call.err
+	}
+	call := &archiveCall{done: make(chan struct{})}
+	r.inflight[id] = call
+	r.mux.Unlock()
+
+	call.data, call.err = b.fetch(id, miss)
+
+	r.mux.Lock()
+	delete(r.inflight, id)
+	if call.err == nil && r.max > 0 {
+		if len(r.order) >= r.max {
+			delete(r.entries, r.order[0])
+			r.order = r.order[1:]
+		}
+		r.entries[id] = call.data
+		r.order = append(r.order, id)
+	}
+	r.mux.Unlock()
+	close(call.done)
+	return call.data, call.err
+}
+
+// prefetch fetches evicted segments starting from id into readback cache
+// in background, up to Config.Prefetch segments.
+func (b *Buffer) prefetch(id int64) {
+	if b.prefetchCount <= 0 {
+		return
+	}
+	go func() {
+		first := b.FirstID()
+		for end := id + b.prefetchCount; id < end && id < first; id++ {
+			if _, err := b.fromArchive(id, ErrMiss); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+// fetch reads segment from Archive and checks its size.
+func (b *Buffer) fetch(id int64, miss error) ([]byte, error) {
+	data, err := b.archive.Segment(id)
+	if errors.Cause(err) == ErrMiss {
+		return nil, miss
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch from archive")
+	}
+	if int64(len(data)) != b.SegmentSize() {
+		return nil, errors.Wrapf(ErrBadSegment, "archived segment %d", id)
+	}
+	return data, nil
+}
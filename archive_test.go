@@ -0,0 +1,132 @@
+package player
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// mapArchive is in-memory Archive that counts fetches.
+type mapArchive struct {
+	mux      sync.Mutex
+	segments map[int64][]byte
+	fetches  int64
+}
+
+func (a *mapArchive) add(id int64, data []byte) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	a.segments[id] = append([]byte(nil), data...)
+}
+
+func (a *mapArchive) Segment(id int64) ([]byte, error) {
+	atomic.AddInt64(&a.fetches, 1)
+	a.mux.Lock()
+	defer a.mux.Unlock()
+	data, ok := a.segments[id]
+	if !ok {
+		return nil, ErrMiss
+	}
+	return data, nil
+}
+
+func TestBuffer_Archive(t *testing.T) {
+	a := &mapArchive{segments: make(map[int64][]byte)}
+	b := New(Config{
+		Count:        2,
+		Segment:      4,
+		Archive:      a,
+		ArchiveCache: 1,
+		OnSegment: func(id int64, _ time.Time, data []byte) {
+			a.add(id, data)
+		},
+	})
+	for i := byte(0); i < 4; i++ {
+		if _, err := b.Write([]byte{i, i, i, i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := make([]byte, 4)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := b.Get(make([]byte, 4), 0); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := b.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{0, 0, 0, 0}) {
+		t.Error("bad segment", buf)
+	}
+	if n := atomic.LoadInt64(&a.fetches); n != 1 {
+		t.Error("segment should be fetched once, got", n)
+	}
+	out := new(bytes.Buffer)
+	if _, err := b.ReadID(out, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), []byte{1, 1, 1, 1}) {
+		t.Error("bad segment", out.Bytes())
+	}
+
+	// not archived
+	if err := b.Get(buf, -1); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	// not written yet
+	if err := b.Get(buf, 10); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func TestCursor_Prefetch(t *testing.T) {
+	a := &mapArchive{segments: make(map[int64][]byte)}
+	b := New(Config{
+		Count:    2,
+		Segment:  4,
+		Archive:  a,
+		Prefetch: 2,
+		OnSegment: func(id int64, _ time.Time, data []byte) {
+			a.add(id, data)
+		},
+	})
+	for i := byte(0); i < 8; i++ {
+		if _, err := b.Write([]byte{i, i, i, i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := b.NewCursor(0)
+	out := new(bytes.Buffer)
+	if _, err := c.ReadNext(out); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReadNext(out); err != nil {
+		t.Fatal(err)
+	}
+	// segments 2 and 3 are prefetched after sequential read of 1
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt64(&a.fetches) < 4 {
+		if time.Now().After(deadline) {
+			t.Fatal("segments should be prefetched")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.ReadNext(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(out.Bytes(), []byte{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 3, 3, 3, 3}) {
+		t.Error("bad data", out.Bytes())
+	}
+}
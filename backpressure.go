@@ -0,0 +1,55 @@
+package player
+
+// Backpressure is policy of Buffer.Write when advancing the window would
+// evict segment that registered Cursor has not read yet.
+type Backpressure int
+
+// Possible Backpressure values.
+const (
+	// BackpressureNone evicts segments regardless of cursors.
+	BackpressureNone Backpressure = iota
+	// BackpressureBlock blocks Write until cursors read segments
+	// that are going to be evicted.
+	BackpressureBlock
+	// BackpressureDrop drops slow cursors that block eviction.
+	BackpressureDrop
+)
+
+// changed returns channel that will be closed on next change of Buffer
+// state, i.e. write or cursor movement. No locks.
+func (b *Buffer) changed() <-chan struct{} {
+	if b.notify == nil {
+		b.notify = make(chan struct{})
+	}
+	return b.notify
+}
+
+// broadcast notifies all waiters about Buffer state change. No locks.
+func (b *Buffer) broadcast() {
+	if b.notify != nil {
+		close(b.notify)
+		b.notify = nil
+	}
+}
+
+// blocking returns live cursors that did not read segments which will be
+// evicted by write of n bytes. No locks.
+func (b *Buffer) blocking(n int64) []*Cursor {
+	overflow := int64(len(b.data)) + n - b.segment*b.maxCount
+	if overflow <= 0 || len(b.cursors) == 0 {
+		return nil
+	}
+	// segments [firstID, limit) will be evicted
+	limit := b.firstID + (overflow+b.segment-1)/b.segment
+	if next := b.nextID(); limit > next {
+		// segments that are not written yet can't be read by cursors
+		limit = next
+	}
+	var cursors []*Cursor
+	for c := range b.cursors {
+		if c.priority == PriorityLive && c.id >= b.firstID && c.id < limit {
+			cursors = append(cursors, c)
+		}
+	}
+	return cursors
+}
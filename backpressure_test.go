@@ -0,0 +1,75 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_BackpressureBlock(t *testing.T) {
+	b := New(Config{
+		Count:        2,
+		Segment:      512,
+		Backpressure: BackpressureBlock,
+	})
+	c := b.NewCursor(0)
+	defer c.Close()
+	buf := make([]byte, 512*2)
+	if _, err := b.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if _, err := b.WriteContext(ctx, buf[:512]); errors.Cause(err) != context.DeadlineExceeded {
+		t.Error(err, "should be", context.DeadlineExceeded)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := b.Write(buf[:512])
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatal("write should block", err)
+	case <-time.After(time.Millisecond * 10):
+	}
+	if _, err := c.ReadNext(new(bytes.Buffer)); err != nil {
+		t.Error(err)
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if b.FirstID() != 1 {
+		t.Error("bad first id", b.FirstID())
+	}
+}
+
+func TestBuffer_BackpressureDrop(t *testing.T) {
+	b := New(Config{
+		Count:        2,
+		Segment:      512,
+		Backpressure: BackpressureDrop,
+	})
+	slow := b.NewCursor(0)
+	fast := b.NewCursor(1)
+	buf := make([]byte, 512*2)
+	if _, err := b.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write(buf[:512]); err != nil {
+		t.Error(err)
+	}
+	if _, err := slow.ReadNext(new(bytes.Buffer)); errors.Cause(err) != ErrDropped {
+		t.Error(err, "should be", ErrDropped)
+	}
+	if _, err := fast.ReadNext(new(bytes.Buffer)); err != nil {
+		t.Error(err)
+	}
+	if len(b.Cursors()) != 1 {
+		t.Error("bad cursors count")
+	}
+}
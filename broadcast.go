@@ -0,0 +1,88 @@
+package player
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// WaitForID blocks until segment with provided id is written or ctx is done.
+// Returns ErrClosed if Buffer is shut down before that.
+func (b *Buffer) WaitForID(ctx context.Context, id int64) error {
+	b.l.Lock()
+	for id >= b.nextID() {
+		if b.closed {
+			b.l.Unlock()
+			return errors.Wrap(ErrClosed, "failed to wait")
+		}
+		changed := b.changed()
+		b.l.Unlock()
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "failed to wait")
+		case <-changed:
+		}
+		b.l.Lock()
+	}
+	defer b.l.Unlock()
+	if id < b.firstID {
+		return errors.Wrap(ErrMiss, "bad id")
+	}
+	return nil
+}
+
+// Broadcast streams new segments to every writer concurrently until ctx
+// is done. Each writer has its own Cursor, so slow writer does not delay
+// others; when it falls behind the window, evicted segments are skipped.
+// Writer that returned error is removed from broadcast. Broadcast returns
+// when ctx is done, every writer failed or Buffer is shut down.
+func (b *Buffer) Broadcast(ctx context.Context, ws ...io.Writer) error {
+	b.l.Lock()
+	live := b.nextID()
+	b.l.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(ws))
+	)
+	for i, w := range ws {
+		wg.Add(1)
+		go func(i int, w io.Writer) {
+			defer wg.Done()
+			errs[i] = b.streamTo(ctx, b.NewCursor(live), w)
+		}(i, w)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "broadcast stopped")
+	}
+	for _, err := range errs {
+		if err != nil {
+			return errors.Wrap(err, "broadcast failed")
+		}
+	}
+	return nil
+}
+
+// streamTo writes segments from cursor to w until error. Closes cursor.
+func (b *Buffer) streamTo(ctx context.Context, c *Cursor, w io.Writer) error {
+	defer c.Close()
+	for {
+		err := b.WaitForID(ctx, c.ID())
+		if err == nil {
+			_, err = c.ReadNext(w)
+		}
+		if errors.Cause(err) == ErrMiss {
+			// cursor is behind the window, skipping evicted segments
+			first := b.FirstID()
+			b.log.Printf("player: %s: slow writer skipped %d segments", b.key, first-c.ID())
+			c.SetID(first)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
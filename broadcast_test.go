@@ -0,0 +1,90 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// syncWriter is io.Writer that is safe for concurrent use.
+type syncWriter struct {
+	l   sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *syncWriter) Len() int {
+	w.l.Lock()
+	defer w.l.Unlock()
+	return w.buf.Len()
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestBuffer_WaitForID(t *testing.T) {
+	b := NewDefault()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := b.WaitForID(ctx, 0); errors.Cause(err) != context.DeadlineExceeded {
+		t.Error(err, "should be", context.DeadlineExceeded)
+	}
+	done := make(chan error)
+	go func() {
+		done <- b.WaitForID(context.Background(), 1)
+	}()
+	buf := make([]byte, b.SegmentSize())
+	for i := 0; i < 2; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuffer_Broadcast(t *testing.T) {
+	b := NewDefault()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, c := new(syncWriter), new(syncWriter)
+	done := make(chan error)
+	go func() {
+		done <- b.Broadcast(ctx, a, c, failWriter{})
+	}()
+	for len(b.Cursors()) != 3 {
+		time.Sleep(time.Millisecond)
+	}
+	r := Rand()
+	if _, err := io.CopyN(b, r, b.SegmentSize()*3); err != nil {
+		t.Error(err)
+	}
+	size := int(b.SegmentSize() * 3)
+	for a.Len() != size || c.Len() != size {
+		time.Sleep(time.Millisecond)
+	}
+	if !bytes.Equal(a.buf.Bytes(), c.buf.Bytes()) {
+		t.Error("data mismatch")
+	}
+	cancel()
+	if err := <-done; errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+	if len(b.Cursors()) != 0 {
+		t.Error("cursors should be closed")
+	}
+}
@@ -0,0 +1,57 @@
+package player
+
+// CatchUp is policy of Cursor that fell behind Buffer window.
+type CatchUp int
+
+// Possible CatchUp values.
+const (
+	// CatchUpFail is default policy: ReadNext returns ErrMiss until
+	// cursor is moved by SetID.
+	CatchUpFail CatchUp = iota
+	// CatchUpSkip jumps to the last complete segment, skipping the
+	// rest of window.
+	CatchUpSkip
+	// CatchUpAccelerate jumps to the first segment of window and
+	// releases segments without pacing until cursor catches up
+	// with live.
+	CatchUpAccelerate
+)
+
+// SetCatchUp sets policy of cursor for falling behind the window.
+// Segments skipped by policy are logged and counted, see Skipped.
+func (c *Cursor) SetCatchUp(p CatchUp) {
+	c.b.l.Lock()
+	c.catchUp = p
+	c.b.l.Unlock()
+}
+
+// Skipped returns total count of segments skipped by catch-up policy.
+func (c *Cursor) Skipped() int64 {
+	c.b.l.RLock()
+	defer c.b.l.RUnlock()
+	return c.skipped
+}
+
+// behind reports whether cursor is behind the window and should be moved
+// by catch-up policy. No locks.
+func (c *Cursor) behind() bool {
+	b := c.b
+	return c.catchUp != CatchUpFail && b.archive == nil && c.id < b.firstID
+}
+
+// catchUpSkip moves cursor that is behind the window according to
+// catch-up policy and returns range [from, to) of skipped ids. Evicted
+// segments are not skipped if they can be read from Archive. No locks.
+func (c *Cursor) catchUpSkip() (from, to int64) {
+	b := c.b
+	if !c.behind() {
+		return 0, 0
+	}
+	from, to = c.id, b.firstID
+	if last := b.nextID() - 1; c.catchUp == CatchUpSkip && last > to {
+		to = last
+	}
+	c.id = to
+	c.skipped += to - from
+	return from, to
+}
@@ -0,0 +1,47 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCursor_SetCatchUp(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 1})
+	fail, skip, accelerate := b.NewCursor(0), b.NewCursor(0), b.NewCursor(0)
+	skip.SetCatchUp(CatchUpSkip)
+	accelerate.SetCatchUp(CatchUpAccelerate)
+	accelerate.SetPace(time.Hour)
+	for _, p := range [][]byte{{0, 1, 2}, {3, 4, 5}} {
+		if _, err := b.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buf := new(bytes.Buffer)
+	if _, err := fail.ReadNext(buf); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if _, err := skip.ReadNext(buf); err != nil || !bytes.Equal(buf.Bytes(), []byte{5}) {
+		t.Error("should skip to live", buf.Bytes(), err)
+	}
+	if n := skip.Skipped(); n != 5 {
+		t.Error("bad skipped count", n)
+	}
+	buf.Reset()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 4; i++ {
+		if _, err := accelerate.ReadNextContext(ctx, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{2, 3, 4, 5}) {
+		t.Error("should read window without pacing", buf.Bytes())
+	}
+	if n := accelerate.Skipped(); n != 2 {
+		t.Error("bad skipped count", n)
+	}
+}
@@ -0,0 +1,134 @@
+package player
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is source of time for Buffer: segment completion times, cursor
+// pacing and stall detection. Default is system clock.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is single event timer created by Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{t: time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+func (t systemTimer) Stop() bool          { return t.t.Stop() }
+
+// FakeClock is Clock that is advanced manually, for deterministic tests
+// of timing behaviour.
+type FakeClock struct {
+	mux    sync.Mutex
+	now    time.Time
+	timers map[*fakeTimer]struct{}
+}
+
+// NewFakeClock creates FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:    now,
+		timers: make(map[*fakeTimer]struct{}),
+	}
+}
+
+// Now returns current time of clock.
+func (c *FakeClock) Now() time.Time {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.now
+}
+
+// NewTimer creates timer that fires when clock is advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	t := &fakeTimer{
+		c:     make(chan time.Time, 1),
+		at:    c.now.Add(d),
+		clock: c,
+	}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers[t] = struct{}{}
+	return t
+}
+
+// Advance moves clock forward by d, firing expired timers.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.now = c.now.Add(d)
+	for t := range c.timers {
+		if !t.at.After(c.now) {
+			t.c <- c.now
+			delete(c.timers, t)
+		}
+	}
+}
+
+// Timers returns count of pending timers, so test can wait until
+// code under test starts waiting before advancing clock.
+func (c *FakeClock) Timers() int {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return len(c.timers)
+}
+
+type fakeTimer struct {
+	c     chan time.Time
+	at    time.Time
+	clock *FakeClock
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mux.Lock()
+	defer t.clock.mux.Unlock()
+	_, pending := t.clock.timers[t]
+	delete(t.clock.timers, t)
+	return pending
+}
+
+// WriteSynthetic writes n segments of synthetic stream to b, advancing
+// clock by segment duration (or by step, if duration is unknown) before
+// each segment. Every byte of segment is equal to low byte of its id.
+func WriteSynthetic(b *Buffer, c *FakeClock, n int, step time.Duration) error {
+	if d := b.Duration(); d > 0 {
+		step = d
+	}
+	seg := make([]byte, b.SegmentSize())
+	for i := 0; i < n; i++ {
+		c.Advance(step)
+		b.l.RLock()
+		id := b.nextID()
+		b.l.RUnlock()
+		for j := range seg {
+			seg[j] = byte(id)
+		}
+		if _, err := b.Write(seg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	t1 := c.NewTimer(time.Second)
+	t2 := c.NewTimer(time.Second * 2)
+	if c.Timers() != 2 {
+		t.Error("bad timers", c.Timers())
+	}
+	c.Advance(time.Second)
+	select {
+	case now := <-t1.C():
+		if !now.Equal(start.Add(time.Second)) {
+			t.Error("bad time", now)
+		}
+	default:
+		t.Error("timer should fire")
+	}
+	if !t2.Stop() {
+		t.Error("timer should be pending")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-t2.C():
+		t.Error("stopped timer should not fire")
+	default:
+	}
+	if !c.Now().Equal(start.Add(time.Second * 2)) {
+		t.Error("bad now", c.Now())
+	}
+}
+
+func TestWriteSynthetic(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	b := New(Config{Count: 4, Segment: 4, Duration: time.Second, Clock: c})
+	if err := WriteSynthetic(b, c, 6, 0); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := b.IDAt(start.Add(time.Second * 4)); err != nil || id != 3 {
+		t.Error(err, id)
+	}
+	buf := make([]byte, 4)
+	if err := b.Get(buf, 5); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{5, 5, 5, 5}) {
+		t.Error("bad segment", buf)
+	}
+}
+
+// waitTimers blocks until c has n pending timers.
+func waitTimers(t *testing.T, c *FakeClock, n int) {
+	deadline := time.Now().Add(time.Second)
+	for c.Timers() < n {
+		if time.Now().After(deadline) {
+			t.Fatal("timer should be created")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBuffer_WatchFakeClock(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	stalls := make(chan time.Time, 1)
+	b := New(Config{
+		Count:   4,
+		Segment: 4,
+		Clock:   c,
+		OnStall: func(last time.Time) {
+			stalls <- last
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, time.Second*10)
+
+	waitTimers(t, c, 1)
+	c.Advance(time.Second * 9)
+	if b.Stalled() {
+		t.Error("should not be stalled before timeout")
+	}
+	c.Advance(time.Second)
+	select {
+	case <-stalls:
+	case <-time.After(time.Second):
+		t.Fatal("stall should be detected")
+	}
+}
+
+func TestCursor_PaceFakeClock(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 4, Clock: c})
+	if err := WriteSynthetic(b, c, 2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	cur := b.NewCursor(0)
+	cur.SetPace(time.Second)
+	if _, err := cur.ReadNext(new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := cur.ReadNext(new(bytes.Buffer))
+		done <- err
+	}()
+	waitTimers(t, c, 1)
+	select {
+	case <-done:
+		t.Fatal("segment should not be released before pace interval")
+	default:
+	}
+	c.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,202 @@
+// Command player-bench simulates publishers writing to Buffers at target
+// bitrate and concurrent readers consuming them via cursors, reporting
+// delivery latency percentiles.
+//
+// Usage:
+//
+//	player-bench [-publishers N] [-readers M] [-bitrate bps] [-duration d] [-lag n]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ernado/player"
+)
+
+type options struct {
+	publishers int
+	readers    int
+	bitrate    int64
+	segment    int64
+	count      int64
+	duration   time.Duration
+	lag        int64
+}
+
+func main() {
+	var (
+		opt        options
+		cpuProfile = flag.String("cpuprofile", "", "write CPU profile to file")
+		memProfile = flag.String("memprofile", "", "write allocation profile to file")
+	)
+	flag.IntVar(&opt.publishers, "publishers", 1, "count of publishers, each with own Buffer")
+	flag.IntVar(&opt.readers, "readers", 100, "count of readers per publisher")
+	flag.Int64Var(&opt.bitrate, "bitrate", 4e6, "publisher bitrate, bits per second")
+	flag.Int64Var(&opt.segment, "segment", 188*1024, "segment size")
+	flag.Int64Var(&opt.count, "count", 32, "segment count")
+	flag.DurationVar(&opt.duration, "duration", time.Second*10, "benchmark duration")
+	flag.Int64Var(&opt.lag, "lag", 0, "maximum start lag of reader in segments, uniformly distributed")
+	flag.Parse()
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			fatal(err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			fatal(err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+	latencies, stats := run(opt)
+	report(os.Stdout, latencies, stats)
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+			fatal(err)
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "player-bench:", err)
+	os.Exit(1)
+}
+
+// run runs benchmark and returns delivery latencies of all readers and
+// stats of all buffers.
+func run(opt options) ([]time.Duration, []player.Stats) {
+	ctx, cancel := context.WithTimeout(context.Background(), opt.duration)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mux       sync.Mutex
+		latencies []time.Duration
+		buffers   = make([]*player.Buffer, opt.publishers)
+	)
+	for i := range buffers {
+		b := player.New(player.Config{
+			Segment: opt.segment,
+			Count:   opt.count,
+		})
+		buffers[i] = b
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			publish(ctx, b, opt)
+		}()
+		for j := 0; j < opt.readers; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				l := read(ctx, b, opt.lag)
+				mux.Lock()
+				latencies = append(latencies, l...)
+				mux.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+	stats := make([]player.Stats, len(buffers))
+	for i, b := range buffers {
+		stats[i] = b.Stats()
+	}
+	return latencies, stats
+}
+
+// publish writes one segment to b per segment duration at opt.bitrate.
+func publish(ctx context.Context, b *player.Buffer, opt options) {
+	seg := make([]byte, opt.segment)
+	rand.Read(seg)
+	interval := time.Duration(opt.segment * 8 * int64(time.Second) / opt.bitrate)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if _, err := b.WriteContext(ctx, seg); err != nil {
+			return
+		}
+	}
+}
+
+// read consumes segments of b via cursor that starts up to lag segments
+// behind live edge, and returns latency of every delivered segment
+// measured from its completion.
+func read(ctx context.Context, b *player.Buffer, lag int64) []time.Duration {
+	start := b.LastID() + 1
+	if lag > 0 {
+		start -= rand.Int63n(lag + 1)
+	}
+	if first := b.FirstID(); start < first {
+		start = first
+	}
+	c := b.NewCursor(start)
+	defer c.Close()
+
+	var latencies []time.Duration
+	for {
+		id := c.ID()
+		if err := b.WaitForID(ctx, id); err != nil {
+			if ctx.Err() != nil {
+				return latencies
+			}
+			// evicted, skipping to window start
+			c.SetID(b.FirstID())
+			continue
+		}
+		completed, err := b.Completed(id)
+		if err != nil {
+			c.SetID(b.FirstID())
+			continue
+		}
+		if _, err := c.ReadNext(io.Discard); err != nil {
+			c.SetID(b.FirstID())
+			continue
+		}
+		latencies = append(latencies, time.Since(completed))
+	}
+}
+
+func report(w io.Writer, latencies []time.Duration, stats []player.Stats) {
+	var total player.Stats
+	for _, s := range stats {
+		total.Writes += s.Writes
+		total.BytesIn += s.BytesIn
+		total.BytesOut += s.BytesOut
+		total.Evictions += s.Evictions
+		total.Hits += s.Hits
+		total.Misses += s.Misses
+	}
+	fmt.Fprintf(w, "writes: %d, in: %d bytes, out: %d bytes\n", total.Writes, total.BytesIn, total.BytesOut)
+	fmt.Fprintf(w, "evictions: %d, hits: %d, misses: %d\n", total.Evictions, total.Hits, total.Misses)
+	if len(latencies) == 0 {
+		fmt.Fprintln(w, "no segments delivered")
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Fprintf(w, "delivered: %d segments\n", len(latencies))
+	for _, p := range []float64{50, 90, 99, 99.9, 100} {
+		i := int(float64(len(latencies)-1) * p / 100)
+		fmt.Fprintf(w, "p%v: %s\n", p, latencies[i])
+	}
+}
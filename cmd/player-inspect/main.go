@@ -0,0 +1,96 @@
+// Command player-inspect prints window and per-segment details of Buffer
+// snapshot written by Buffer.Encode.
+//
+// Usage:
+//
+//	player-inspect [-segments] snapshot
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ernado/player"
+)
+
+func main() {
+	segments := flag.Bool("segments", true, "print per-segment details")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: player-inspect [-segments] snapshot")
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *segments); err != nil {
+		fmt.Fprintln(os.Stderr, "player-inspect:", err)
+		os.Exit(1)
+	}
+}
+
+func run(name string, segments bool) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b := player.NewDefault()
+	if err := b.Decode(f); err != nil {
+		return err
+	}
+
+	first, last, present := b.Window()
+	gaps := 0
+	for _, ok := range present {
+		if !ok {
+			gaps++
+		}
+	}
+	fmt.Printf("segment size: %d\n", b.SegmentSize())
+	fmt.Printf("count: %d/%d\n", len(present), b.Count())
+	fmt.Printf("duration: %s\n", b.Duration())
+	fmt.Printf("generation: %d\n", b.Generation())
+	fmt.Printf("window: [%d, %d], %d gaps\n", first, last, gaps)
+	fmt.Printf("partial: %d bytes\n", int64(b.Size())%b.SegmentSize())
+	if !segments || len(present) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCOMPLETED\tINTERVAL\tCRC32\tMETA\tDISC")
+	buf := make([]byte, b.SegmentSize())
+	var prev time.Time
+	for id := first; id <= last; id++ {
+		if !present[id-first] {
+			fmt.Fprintf(w, "%d\t-\t-\t-\t-\t-\n", id)
+			continue
+		}
+		if err := b.Get(buf, id); err != nil {
+			return err
+		}
+		completed, err := b.Completed(id)
+		if err != nil {
+			return err
+		}
+		meta, err := b.Meta(id)
+		if err != nil {
+			return err
+		}
+		disc, err := b.Discontinuity(id)
+		if err != nil {
+			return err
+		}
+		interval := "-"
+		if !prev.IsZero() {
+			interval = completed.Sub(prev).String()
+		}
+		prev = completed
+		fmt.Fprintf(w, "%d\t%s\t%s\t%08x\t%d\t%t\n",
+			id, completed.Format(time.RFC3339Nano), interval,
+			crc32.ChecksumIEEE(buf), len(meta), disc,
+		)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,106 @@
+package player
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// WriteString appends internal buffer with s, implementing
+// io.StringWriter. With Coalesce, s is staged without conversion.
+func (b *Buffer) WriteString(s string) (int, error) {
+	if !b.coalesce {
+		return b.Write([]byte(s))
+	}
+	b.sl.Lock()
+	defer b.sl.Unlock()
+	if err := b.stage(context.Background(), len(s)); err != nil {
+		return 0, err
+	}
+	n := len(b.staged)
+	b.staged = append(b.staged, s...)
+	if err := b.drain(context.Background(), false); err != nil {
+		b.staged = b.staged[:n]
+		return 0, err
+	}
+	return len(s), nil
+}
+
+// writeStaged stages buf and writes staged data to Buffer if it
+// completes segment. On failure only buf is discarded, data staged by
+// previous writes is kept.
+func (b *Buffer) writeStaged(ctx context.Context, buf []byte) (int, error) {
+	b.sl.Lock()
+	defer b.sl.Unlock()
+	if err := b.stage(ctx, len(buf)); err != nil {
+		return 0, err
+	}
+	n := len(b.staged)
+	b.staged = append(b.staged, buf...)
+	if err := b.drain(ctx, false); err != nil {
+		b.staged = b.staged[:n]
+		return 0, err
+	}
+	return len(buf), nil
+}
+
+// stage checks that write of n bytes can be staged, as Write does, and
+// writes staged data if n bytes would not fit into window with it.
+// Should be called with sl locked.
+func (b *Buffer) stage(ctx context.Context, n int) error {
+	b.l.RLock()
+	closed, size, overflow := b.closed, b.segment*b.maxCount, b.allowOverflow
+	b.l.RUnlock()
+	if closed {
+		return errors.Wrap(ErrClosed, "failed to write")
+	}
+	if int64(n) > size && !overflow {
+		return errors.Wrap(ErrTooLargeWrite, "failed to write")
+	}
+	if int64(len(b.staged)+n) > size {
+		return b.drain(ctx, true)
+	}
+	return nil
+}
+
+// drain writes staged data to Buffer if it completes segment or force
+// is set. Staged data is kept on failure. Should be called with sl
+// locked.
+func (b *Buffer) drain(ctx context.Context, force bool) error {
+	if len(b.staged) == 0 {
+		return nil
+	}
+	if !force && int64(len(b.staged)) < atomic.LoadInt64(&b.pubNeed) {
+		return nil
+	}
+	if _, err := b.write(ctx, -1, b.staged); err != nil {
+		return err
+	}
+	b.staged = b.staged[:0]
+	return nil
+}
+
+// staging returns count of staged bytes.
+func (b *Buffer) staging() int64 {
+	if !b.coalesce {
+		return 0
+	}
+	b.sl.Lock()
+	defer b.sl.Unlock()
+	return int64(len(b.staged))
+}
+
+// dropPartial drops trailing partial segment, including staged bytes,
+// and returns count of dropped bytes. Should be called with sl and l
+// locked.
+func (b *Buffer) dropPartial() int64 {
+	partial := int64(len(b.data)) % b.segment
+	dropped := partial + int64(len(b.staged))
+	b.staged = b.staged[:0]
+	if partial > 0 {
+		b.data = b.data[:int64(len(b.data))-partial]
+		b.publish()
+	}
+	return dropped
+}
@@ -0,0 +1,105 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Coalesce(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4, Coalesce: true})
+	write := func(p ...byte) {
+		t.Helper()
+		if _, err := b.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(1, 1, 1)
+	if b.Size() != 0 || b.Partial() != 3 {
+		t.Error("write should be staged", b.Size(), b.Partial())
+	}
+	write(1, 2)
+	if b.Size() != 5 || b.Partial() != 1 {
+		t.Error("staged data should be written", b.Size(), b.Partial())
+	}
+	write(2, 2)
+	if _, err := io.WriteString(b, "\x02"); err != nil {
+		t.Fatal(err)
+	}
+	if b.Size() != 8 || b.Stats().Writes != 2 {
+		t.Error("bad coalescing", b.Size(), b.Stats().Writes)
+	}
+	write(3)
+	if n, err := b.Flush(0); err != nil || n != 3 {
+		t.Fatal("bad flush", n, err)
+	}
+	var got []byte
+	buf := make([]byte, 4)
+	for id := int64(0); id < 3; id++ {
+		if err := b.Get(buf, id); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, buf...)
+	}
+	if !bytes.Equal(got, []byte{1, 1, 1, 1, 2, 2, 2, 2, 3, 0, 0, 0}) {
+		t.Error("bad data", got)
+	}
+}
+
+func BenchmarkBuffer_CoalescedWrite(b *testing.B) {
+	buf := New(Config{Count: 64, Segment: 188 * 7 * 10, Coalesce: true})
+	packet := make([]byte, 188)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(packet)))
+	for i := 0; i < b.N; i++ {
+		if _, err := buf.Write(packet); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBuffer_CoalesceWriteSegment(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4, Coalesce: true})
+	if _, err := b.Write([]byte{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteSegment(context.Background(), 0, []byte{2, 2, 2, 2}); errors.Cause(err) != ErrOutOfOrder {
+		t.Error(err, "should be", ErrOutOfOrder)
+	}
+}
+
+func TestBuffer_CoalesceRejected(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4, Coalesce: true})
+	if _, err := b.Write([]byte("ab")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write(make([]byte, 9)); errors.Cause(err) != ErrTooLargeWrite {
+		t.Error(err, "should be", ErrTooLargeWrite)
+	}
+	if _, err := b.Write([]byte("cd")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if err := b.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "abcd" {
+		t.Errorf("acknowledged data should be kept, got %q", buf)
+	}
+	// staged data with write that fills the window
+	if _, err := b.Write([]byte("ef")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte("ghijklmn")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Get(buf, 2); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ijkl" {
+		t.Errorf("bad data %q", buf)
+	}
+}
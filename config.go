@@ -0,0 +1,95 @@
+package player
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBadConfig indicates invalid Config.
+const ErrBadConfig Error = "bad config"
+
+var backpressureNames = map[Backpressure]string{
+	BackpressureNone:  "none",
+	BackpressureBlock: "block",
+	BackpressureDrop:  "drop",
+}
+
+func (p Backpressure) String() string {
+	if s, ok := backpressureNames[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Backpressure(%d)", int(p))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Backpressure) MarshalText() ([]byte, error) {
+	if _, ok := backpressureNames[p]; !ok {
+		return nil, errors.Wrapf(ErrBadConfig, "unknown backpressure %d", int(p))
+	}
+	return []byte(p.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Backpressure) UnmarshalText(text []byte) error {
+	for v, s := range backpressureNames {
+		if s == string(text) {
+			*p = v
+			return nil
+		}
+	}
+	return errors.Wrapf(ErrBadConfig, "unknown backpressure %q", text)
+}
+
+// Validate checks that Config values are consistent. Zero values are
+// valid and replaced by defaults in New.
+func (cfg Config) Validate() error {
+	switch {
+	case cfg.Segment < 0:
+		return errors.Wrap(ErrBadConfig, "negative segment size")
+	case cfg.Count < 0:
+		return errors.Wrap(ErrBadConfig, "negative count")
+	case cfg.Start < 0:
+		return errors.Wrap(ErrBadConfig, "negative start id")
+	case cfg.Duration < 0:
+		return errors.Wrap(ErrBadConfig, "negative duration")
+	case cfg.ArchiveCache < 0:
+		return errors.Wrap(ErrBadConfig, "negative archive cache")
+	case cfg.EpochStart && cfg.Duration <= 0:
+		return errors.Wrap(ErrBadConfig, "epoch start requires duration")
+	case cfg.Prefetch < 0:
+		return errors.Wrap(ErrBadConfig, "negative prefetch")
+	case cfg.ArchiveCache > 0 && int64(cfg.ArchiveCache) < cfg.Prefetch:
+		return errors.Wrap(ErrBadConfig, "archive cache is smaller than prefetch")
+	}
+	if _, ok := backpressureNames[cfg.Backpressure]; !ok {
+		return errors.Wrapf(ErrBadConfig, "unknown backpressure %d", int(cfg.Backpressure))
+	}
+	return nil
+}
+
+// Reconfigure applies reloadable subset of cfg to running Buffer:
+// Segment (via Resegment), Count, Duration, AllowOverflow, Backpressure
+// and MarkDiscontinuity. Zero Segment and Count are left unchanged.
+// Other values are ignored.
+func (b *Buffer) Reconfigure(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.Segment > 0 && cfg.Segment != b.SegmentSize() {
+		if err := b.Resegment(cfg.Segment); err != nil {
+			return errors.Wrap(err, "failed to reconfigure")
+		}
+	}
+	if cfg.Count > 0 {
+		b.SetCount(cfg.Count)
+	}
+	b.l.Lock()
+	b.duration = cfg.Duration
+	b.allowOverflow = cfg.AllowOverflow
+	b.backpressure = cfg.Backpressure
+	b.markDiscontinuity = cfg.MarkDiscontinuity
+	b.broadcast() // backpressure policy could be relaxed
+	b.l.Unlock()
+	return nil
+}
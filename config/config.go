@@ -0,0 +1,46 @@
+// Package config loads player.Config from JSON, e.g. from configuration
+// file of origin server. Validation and hot-reload of loaded Config are
+// done by player.Config.Validate and player.Buffer.Reconfigure, which
+// need state of Buffer.
+package config
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ernado/player"
+)
+
+// file is JSON representation of player.Config with duration as string,
+// e.g. "2s".
+type file struct {
+	player.Config
+	Duration string `json:"duration"`
+}
+
+// Load decodes JSON Config from r and validates it. Duration is parsed
+// by time.ParseDuration. Hooks and other func or interface values can't
+// be loaded and should be set by caller.
+func Load(r io.Reader) (player.Config, error) {
+	var f file
+	d := json.NewDecoder(r)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&f); err != nil {
+		return player.Config{}, errors.Wrap(err, "failed to decode")
+	}
+	cfg := f.Config
+	if f.Duration != "" {
+		duration, err := time.ParseDuration(f.Duration)
+		if err != nil {
+			return player.Config{}, errors.Wrap(player.ErrBadConfig, err.Error())
+		}
+		cfg.Duration = duration
+	}
+	if err := cfg.Validate(); err != nil {
+		return player.Config{}, err
+	}
+	return cfg, nil
+}
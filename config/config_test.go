@@ -0,0 +1,36 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/ernado/player"
+)
+
+func TestLoad(t *testing.T) {
+	cfg, err := Load(strings.NewReader(`{"key": "live", "segment": 188, "count": 16, "duration": "2s", "backpressure": "drop"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Key != "live" || cfg.Segment != 188 || cfg.Count != 16 || cfg.Duration != time.Second*2 || cfg.Backpressure != player.BackpressureDrop {
+		t.Errorf("bad config %+v", cfg)
+	}
+	for _, s := range []string{
+		`{"count": -1}`,
+		`{"duration": "2"}`,
+		`{"duration": "-1s"}`,
+	} {
+		if _, err := Load(strings.NewReader(s)); errors.Cause(err) != player.ErrBadConfig {
+			t.Error(s, err, "should be", player.ErrBadConfig)
+		}
+	}
+	if _, err := Load(strings.NewReader(`{"backpressure": "wait"}`)); err == nil {
+		t.Error("should fail on unknown backpressure")
+	}
+	if _, err := Load(strings.NewReader(`{"unknown": 1}`)); err == nil {
+		t.Error("should fail on unknown field")
+	}
+}
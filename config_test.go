@@ -0,0 +1,75 @@
+package player
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	for _, cfg := range []Config{
+		{Segment: -1},
+		{Count: -1},
+		{Start: -1},
+		{Duration: -time.Second},
+		{Backpressure: 10},
+	} {
+		if err := cfg.Validate(); errors.Cause(err) != ErrBadConfig {
+			t.Error(err, "should be", ErrBadConfig)
+		}
+	}
+	if err := (Config{}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuffer_Reconfigure(t *testing.T) {
+	b := New(Config{Segment: 4, Count: 4})
+	if _, err := b.Write(make([]byte, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Reconfigure(Config{Count: 2, Duration: time.Second, Backpressure: BackpressureBlock}); err != nil {
+		t.Fatal(err)
+	}
+	if b.Count() != 2 || b.Duration() != time.Second || b.SegmentSize() != 4 {
+		t.Error("bad reconfigured buffer", b.Count(), b.Duration(), b.SegmentSize())
+	}
+	if first := b.FirstID(); first != 2 {
+		t.Error("bad first id", first)
+	}
+	if err := b.Reconfigure(Config{Segment: 8}); err != nil {
+		t.Fatal(err)
+	}
+	if b.SegmentSize() != 8 {
+		t.Error("bad segment size", b.SegmentSize())
+	}
+	if err := b.Reconfigure(Config{Count: -1}); errors.Cause(err) != ErrBadConfig {
+		t.Error(err, "should be", ErrBadConfig)
+	}
+}
+
+func TestConfig_ValidatePrefetch(t *testing.T) {
+	if err := (Config{Prefetch: 4, ArchiveCache: 2}).Validate(); errors.Cause(err) != ErrBadConfig {
+		t.Error(err, "should be", ErrBadConfig)
+	}
+	if err := (Config{Prefetch: 4}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBackpressure_MarshalText(t *testing.T) {
+	data, err := json.Marshal(Config{Backpressure: BackpressureDrop})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"backpressure":"drop"`) {
+		t.Error("bad json", string(data))
+	}
+	var cfg Config
+	if err := json.Unmarshal([]byte(`{"backpressure": "block"}`), &cfg); err != nil || cfg.Backpressure != BackpressureBlock {
+		t.Error("bad backpressure", cfg.Backpressure, err)
+	}
+}
@@ -0,0 +1,277 @@
+package player
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Cursor is persistent reading position in Buffer, e.g. of connected client.
+// Cursor is tracked by Buffer until closed.
+type Cursor struct {
+	b         *Buffer
+	id        int64 // next segment id to read
+	delivered int64 // total bytes written by ReadNext
+	dropped   bool  // cursor was dropped by backpressure policy
+	pace      time.Duration
+	released  time.Time // release time of last segment, if paced
+	last      int64     // id of last read segment, -1 if none
+	priority  Priority
+	delay     time.Duration
+	catchUp   CatchUp
+	skipped   int64 // total count of segments skipped by catch-up policy
+}
+
+// CursorStats is aggregate statistics for all cursors of Buffer.
+type CursorStats struct {
+	Count     int   // count of cursors
+	MaxLag    int64 // maximum lag in segments
+	Delivered int64 // total bytes delivered
+}
+
+// NewCursor creates and registers new cursor that starts reading
+// from segment with provided id.
+func (b *Buffer) NewCursor(id int64) *Cursor {
+	b.l.Lock()
+	defer b.l.Unlock()
+	return b.newCursor(id)
+}
+
+// newCursor creates and registers new cursor. No locks.
+func (b *Buffer) newCursor(id int64) *Cursor {
+	c := &Cursor{
+		b:    b,
+		id:   id,
+		last: -1,
+	}
+	if b.cursors == nil {
+		b.cursors = make(map[*Cursor]struct{})
+	}
+	b.cursors[c] = struct{}{}
+	return c
+}
+
+// Cursors returns all registered cursors.
+func (b *Buffer) Cursors() []*Cursor {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	cursors := make([]*Cursor, 0, len(b.cursors))
+	for c := range b.cursors {
+		cursors = append(cursors, c)
+	}
+	return cursors
+}
+
+// CursorStats returns aggregate statistics for registered cursors.
+func (b *Buffer) CursorStats() CursorStats {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	s := CursorStats{Count: len(b.cursors)}
+	for c := range b.cursors {
+		if lag := c.lag(); lag > s.MaxLag {
+			s.MaxLag = lag
+		}
+		s.Delivered += c.delivered
+	}
+	return s
+}
+
+// lag returns count of written segments that cursor did not read yet.
+// No locks.
+func (c *Cursor) lag() int64 {
+	lag := c.b.nextID() - c.id
+	if lag < 0 {
+		return 0
+	}
+	return lag
+}
+
+// ID returns id of next segment to read.
+func (c *Cursor) ID() int64 {
+	c.b.l.RLock()
+	defer c.b.l.RUnlock()
+	return c.id
+}
+
+// Delivered returns total count of bytes delivered by cursor.
+func (c *Cursor) Delivered() int64 {
+	c.b.l.RLock()
+	defer c.b.l.RUnlock()
+	return c.delivered
+}
+
+// Lag returns count of segments cursor is behind LastID.
+func (c *Cursor) Lag() int64 {
+	c.b.l.RLock()
+	defer c.b.l.RUnlock()
+	return c.lag()
+}
+
+// SetID sets id of next segment to read.
+func (c *Cursor) SetID(id int64) {
+	c.b.l.Lock()
+	c.id = id
+	c.b.broadcast()
+	c.b.l.Unlock()
+}
+
+// SetPace sets minimum interval between segments released by ReadNext,
+// emulating real-time playback. Usually it is Buffer.Duration. Zero
+// disables pacing.
+func (c *Cursor) SetPace(d time.Duration) {
+	c.b.l.Lock()
+	c.pace = d
+	c.b.l.Unlock()
+}
+
+// ReadNext writes next segment to w and advances cursor.
+func (c *Cursor) ReadNext(w io.Writer) (int, error) {
+	return c.ReadNextContext(context.Background(), w)
+}
+
+// ReadNextContext is ReadNext that can be cancelled while waiting for
+// release of paced segment.
+func (c *Cursor) ReadNextContext(ctx context.Context, w io.Writer) (int, error) {
+	n, _, err := c.read(ctx, w)
+	return n, err
+}
+
+// ReadNextMeta is ReadNext that also returns metadata events attached
+// to the delivered segment, captured atomically with segment data.
+func (c *Cursor) ReadNextMeta(w io.Writer) (int, [][]byte, error) {
+	return c.read(context.Background(), w)
+}
+
+// read writes next segment to w, advances cursor and returns metadata
+// of the segment. Segment is copied under read lock, so cursors share
+// the lock with other readers.
+func (c *Cursor) read(ctx context.Context, w io.Writer) (n int, meta [][]byte, err error) {
+	b := c.b
+	var (
+		id     int64
+		traced bool
+	)
+	defer func() {
+		if !traced {
+			b.traceGet("Cursor.ReadNext", id, n, err)
+		}
+	}()
+
+	b.l.RLock() // should be unlocked before w.Write call
+	id = c.id
+	if c.dropped {
+		b.l.RUnlock()
+		return 0, nil, errors.Wrap(ErrDropped, "failed to read")
+	}
+	if c.delay > 0 && b.shortWindow(c.delay) {
+		b.l.RUnlock()
+		return 0, nil, errors.Wrap(ErrShortWindow, "failed to read")
+	}
+	for c.behind() {
+		// moving cursor needs write lock
+		b.l.RUnlock()
+		b.l.Lock()
+		skipFrom, skipTo := c.catchUpSkip()
+		b.l.Unlock()
+		if skipTo > skipFrom {
+			b.log.Printf("player: %s: cursor skipped segments %d-%d", b.key, skipFrom, skipTo-1)
+		}
+		b.l.RLock()
+	}
+	id = c.id
+	pace, release := c.pace, c.released.Add(c.pace)
+	if c.catchUp == CatchUpAccelerate && c.lag() > 1 {
+		// behind live, releasing without pacing until caught up
+		pace = 0
+	}
+	var delayed time.Time // release time of delayed segment
+	var p *[]byte
+	if err := b.acquireID(id); err != nil {
+		evicted := b.archive != nil && id < b.firstID
+		// background cursors do not prefetch to keep cache for live ones
+		prefetch := c.priority == PriorityLive && c.last >= 0 && c.last == id-1
+		b.l.RUnlock()
+		if !evicted {
+			return 0, nil, errors.Wrap(err, "bad id")
+		}
+		data, err := b.fromArchive(id, err)
+		if err != nil {
+			return 0, nil, errors.Wrap(err, "bad id")
+		}
+		if prefetch {
+			b.prefetch(id + 1)
+		}
+		p = b.getBuffer(int64(len(data)))
+		copy(*p, data)
+	} else {
+		p = b.getBuffer(b.segment)
+		copy(*p, b.getSegment(id))
+		meta = b.meta[id]
+		if c.delay > 0 {
+			delayed = b.times[id-b.firstID].Add(c.delay)
+		}
+		b.l.RUnlock()
+	}
+	defer b.putBuffer(p)
+
+	buf, err := b.transformRead(id, *p)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "failed to transform")
+	}
+	if err := sleepUntil(ctx, b.clock, delayed); err != nil {
+		return 0, nil, errors.Wrap(err, "failed to read")
+	}
+	if pace > 0 {
+		if err := c.wait(ctx, release); err != nil {
+			return 0, nil, err
+		}
+	}
+	// span measures serving time only, without pacing and delay
+	traced = true
+	span := b.tracer.Start(ctx, SpanInfo{Op: "Cursor.ReadNext", Key: b.key, ID: id})
+	n, err = w.Write(buf)
+	span.End(n, err)
+	b.l.Lock()
+	c.delivered += int64(n)
+	if err == nil && c.id == id {
+		c.id++
+		c.last = id
+		if b.waiting > 0 {
+			b.broadcast()
+		}
+	}
+	b.l.Unlock()
+	return n, meta, err
+}
+
+// wait blocks until release time of paced segment and records it.
+func (c *Cursor) wait(ctx context.Context, release time.Time) error {
+	now := c.b.clock.Now()
+	if d := release.Sub(now); d > 0 {
+		t := c.b.clock.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "failed to read")
+		case <-t.C():
+		}
+	} else {
+		// reader is late, pacing from now
+		release = now
+	}
+	c.b.l.Lock()
+	c.released = release
+	c.b.l.Unlock()
+	return nil
+}
+
+// Close unregisters cursor from Buffer.
+func (c *Cursor) Close() error {
+	c.b.l.Lock()
+	delete(c.b.cursors, c)
+	c.b.broadcast()
+	c.b.l.Unlock()
+	return nil
+}
@@ -0,0 +1,139 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCursor_ReadNext(t *testing.T) {
+	r := Rand()
+	b := NewDefault()
+	c := b.NewCursor(0)
+	defer c.Close()
+	if c.Lag() != 0 {
+		t.Error("bad lag", c.Lag())
+	}
+	data := new(bytes.Buffer)
+	if _, err := io.CopyN(io.MultiWriter(b, data), r, b.SegmentSize()*3); err != nil {
+		t.Error(err)
+	}
+	if c.Lag() != 3 {
+		t.Error("bad lag", c.Lag())
+	}
+	buf := new(bytes.Buffer)
+	for i := 0; i < 3; i++ {
+		if _, err := c.ReadNext(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	if !bytes.Equal(buf.Bytes(), data.Bytes()) {
+		t.Error("data mismatch")
+	}
+	if c.ID() != 3 || c.Lag() != 0 {
+		t.Error("bad position", c.ID(), c.Lag())
+	}
+	if c.Delivered() != b.SegmentSize()*3 {
+		t.Error("bad delivered", c.Delivered())
+	}
+	if _, err := c.ReadNext(buf); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func TestBuffer_Cursors(t *testing.T) {
+	b := NewDefault()
+	a := b.NewCursor(0)
+	c := b.NewCursor(2)
+	if len(b.Cursors()) != 2 {
+		t.Error("bad cursors count")
+	}
+	buf := make([]byte, b.SegmentSize()*4)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.ReadNext(new(bytes.Buffer)); err != nil {
+		t.Error(err)
+	}
+	s := b.CursorStats()
+	if s.Count != 2 || s.MaxLag != 4 || s.Delivered != b.SegmentSize() {
+		t.Errorf("bad stats %+v", s)
+	}
+	if err := a.Close(); err != nil {
+		t.Error(err)
+	}
+	if s = b.CursorStats(); s.Count != 1 || s.MaxLag != 1 {
+		t.Errorf("bad stats %+v", s)
+	}
+}
+
+func TestCursor_SetPace(t *testing.T) {
+	b := New(Config{
+		Duration: time.Millisecond * 20,
+	})
+	c := b.NewCursor(0)
+	defer c.Close()
+	c.SetPace(b.Duration())
+	buf := make([]byte, b.SegmentSize()*3)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.ReadNext(io.Discard); err != nil {
+			t.Error(err)
+		}
+	}
+	if d := time.Since(start); d < b.Duration()*2 {
+		t.Error("segments released too fast", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Write(buf[:b.SegmentSize()]); err != nil {
+		t.Error(err)
+	}
+	if _, err := c.ReadNextContext(ctx, io.Discard); errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+}
+
+func TestCursor_ReadNextShared(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	if _, err := b.Write(make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	c := b.NewCursor(0)
+	defer c.Close()
+	b.l.Lock()
+	changed := b.changed()
+	b.l.Unlock()
+
+	// segment is read under shared lock
+	w := newBlockingWriter()
+	done := make(chan error, 1)
+	b.l.RLock()
+	go func() {
+		_, err := c.ReadNext(w)
+		done <- err
+	}()
+	select {
+	case <-w.entered:
+	case <-time.After(time.Second):
+		t.Fatal("read should not take exclusive lock")
+	}
+	b.l.RUnlock()
+	close(w.gate)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+		t.Error("read should not notify without waiters")
+	default:
+	}
+}
@@ -0,0 +1,23 @@
+package player
+
+import "time"
+
+// ErrShortWindow means that Buffer window is shorter than delay of
+// time-shifted cursor, so segments it should play are already evicted.
+const ErrShortWindow Error = "window is shorter than delay"
+
+// SetDelay makes cursor stay d behind live, e.g. for profanity delay:
+// ReadNext waits until segment was completed at least d ago. If window of
+// Buffer becomes shorter than d, ReadNext returns ErrShortWindow. Zero
+// disables delay.
+func (c *Cursor) SetDelay(d time.Duration) {
+	c.b.l.Lock()
+	c.delay = d
+	c.b.l.Unlock()
+}
+
+// shortWindow reports whether segments completed less than d ago were
+// evicted. No locks.
+func (b *Buffer) shortWindow(d time.Duration) bool {
+	return !b.evicted.IsZero() && b.clock.Now().Sub(b.evicted) < d
+}
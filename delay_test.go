@@ -0,0 +1,55 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestCursor_SetDelay(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 2, Clock: c})
+	cursor := b.NewCursor(0)
+	cursor.SetDelay(10 * time.Second)
+	if _, err := b.Write([]byte{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	buf := new(bytes.Buffer)
+	go func() {
+		_, err := cursor.ReadNextContext(context.Background(), buf)
+		done <- err
+	}()
+	waitTimers(t, c, 1)
+	c.Advance(9 * time.Second)
+	select {
+	case err := <-done:
+		t.Fatal("segment should be delayed", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 1}) {
+		t.Error("bad segment", buf.Bytes())
+	}
+
+	// window of 4 segments written every second is shorter than delay
+	for i := 0; i < 5; i++ {
+		c.Advance(time.Second)
+		if _, err := b.Write([]byte{2, 2}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := cursor.ReadNext(buf); errors.Cause(err) != ErrShortWindow {
+		t.Error(err, "should be", ErrShortWindow)
+	}
+	cursor.SetDelay(0)
+	if _, err := cursor.ReadNext(buf); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
@@ -0,0 +1,141 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// delta format constants.
+const (
+	deltaMagic   = "PLDT"
+	deltaVersion = 1
+)
+
+// EncodeDelta writes segments with ids after since, with their metadata,
+// to w in compact binary format, so edge cache that already has
+// segments up to since can pull only new ones and apply them by
+// ApplyDelta. Segments that are already evicted are skipped.
+func (b *Buffer) EncodeDelta(w io.Writer, since int64) error {
+	b.l.RLock() // should be unlocked before writing to w
+	from := since + 1
+	if from < b.firstID {
+		from = b.firstID
+	}
+	to := b.nextID()
+	if from > to {
+		from = to
+	}
+	generation, size := b.generation, b.segment
+	// data of complete segments and their events are never modified
+	segments := make([][]byte, 0, to-from)
+	meta := make([][][]byte, 0, to-from)
+	for id := from; id < to; id++ {
+		start := b.segment * (id - b.firstID)
+		segments = append(segments, b.data[start:start+b.segment])
+		meta = append(meta, b.meta[id])
+	}
+	b.l.RUnlock()
+
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+	_, e.err = bw.WriteString(deltaMagic)
+	e.int(deltaVersion)
+	e.int(generation)
+	e.int(size)
+	e.int(from)
+	e.int(to - from)
+	for i, seg := range segments {
+		e.int(int64(len(meta[i])))
+		for _, event := range meta[i] {
+			e.bytes(event)
+		}
+		if e.err == nil {
+			_, e.err = bw.Write(seg)
+		}
+	}
+	if e.err != nil {
+		return errors.Wrap(e.err, "failed to encode delta")
+	}
+	return errors.Wrap(bw.Flush(), "failed to encode delta")
+}
+
+// ApplyDelta writes segments and metadata from delta written by
+// EncodeDelta to Buffer and returns id of last segment in Buffer.
+// Segments that Buffer already has are skipped. Returns ErrOutOfOrder if
+// delta starts after next segment of Buffer, e.g. because edge fell
+// behind the origin window; state should be re-synced from snapshot
+// then. Segments are written as stored by origin, so edge Buffer usually
+// has no Transforms.
+func (b *Buffer) ApplyDelta(ctx context.Context, r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(deltaMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != deltaMagic {
+		return 0, errors.Wrap(ErrBadSnapshot, "bad delta magic")
+	}
+	d := &decoder{r: br}
+	if v := d.int(); d.err == nil && v != deltaVersion {
+		return 0, errors.Wrapf(ErrBadSnapshot, "unsupported delta version %d", v)
+	}
+	d.int() // generation of origin, informational
+	size := d.int()
+	from := d.int()
+	count := d.int()
+	if d.err != nil {
+		return 0, errors.Wrap(d.err, "failed to read delta")
+	}
+	if size != b.SegmentSize() || count < 0 {
+		return 0, errors.Wrap(ErrBadSnapshot, "bad delta segment size")
+	}
+	seg := make([]byte, size)
+	for id := from; id < from+count; id++ {
+		var events [][]byte
+		for n := d.int(); d.err == nil && n > 0; n-- {
+			events = append(events, d.bytes())
+		}
+		if d.err == nil {
+			_, d.err = io.ReadFull(br, seg)
+		}
+		if d.err != nil {
+			return 0, errors.Wrap(d.err, "failed to read delta")
+		}
+		if err := b.WriteSegment(ctx, id, seg); err != nil {
+			return 0, errors.Wrap(err, "failed to apply delta")
+		}
+		b.l.Lock()
+		if _, ok := b.meta[id]; !ok && id >= b.firstID {
+			for _, event := range events {
+				b.addMeta(id, event)
+			}
+		}
+		b.l.Unlock()
+	}
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.nextID() - 1, nil
+}
+
+// DeltaHandler is http.Handler that writes delta of Buffer since segment
+// id from "since" query parameter, see EncodeDelta.
+type DeltaHandler struct {
+	Buffer *Buffer
+}
+
+func (h DeltaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	since := int64(-1)
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = strconv.ParseInt(v, 10, 64); err != nil {
+			http.Error(w, "bad since", http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if err := h.Buffer.EncodeDelta(w, since); err != nil {
+		h.Buffer.log.Printf("player: %s: %v", h.Buffer.key, err)
+	}
+}
@@ -0,0 +1,137 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Delta(t *testing.T) {
+	ctx := context.Background()
+	origin := New(Config{Count: 4, Segment: 4})
+	edge := New(Config{Count: 4, Segment: 4})
+	if _, err := origin.Write([]byte{0, 0, 0, 0, 1, 1, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	origin.WriteMeta([]byte("title"))
+	if _, err := origin.Write([]byte{2, 2, 2, 2, 3, 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	delta := new(bytes.Buffer)
+	if err := origin.EncodeDelta(delta, -1); err != nil {
+		t.Fatal(err)
+	}
+	last, err := edge.ApplyDelta(ctx, bytes.NewReader(delta.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 2 {
+		t.Error("bad last id", last)
+	}
+	// applying same delta again is no-op
+	if _, err := edge.ApplyDelta(ctx, bytes.NewReader(delta.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if meta, err := edge.Meta(2); err != nil || len(meta) != 1 || string(meta[0]) != "title" {
+		t.Error("bad meta", meta, err)
+	}
+
+	if _, err := origin.Write([]byte{3, 3}); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	DeltaHandler{Buffer: origin}.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?since=2", nil))
+	if last, err = edge.ApplyDelta(ctx, w.Body); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if err := edge.Get(buf, last); err != nil {
+		t.Fatal(err)
+	}
+	if last != 3 || !bytes.Equal(buf, []byte{3, 3, 3, 3}) {
+		t.Error("bad segment", last, buf)
+	}
+
+	// edge is behind origin window
+	if _, err := origin.Write(bytes.Repeat([]byte{4}, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := origin.Write(bytes.Repeat([]byte{5}, 12)); err != nil {
+		t.Fatal(err)
+	}
+	delta.Reset()
+	if err := origin.EncodeDelta(delta, last); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := edge.ApplyDelta(ctx, delta); errors.Cause(err) != ErrOutOfOrder {
+		t.Error(err, "should be", ErrOutOfOrder)
+	}
+	if _, err := edge.ApplyDelta(ctx, bytes.NewReader([]byte("garbage"))); errors.Cause(err) != ErrBadSnapshot {
+		t.Error(err, "should be", ErrBadSnapshot)
+	}
+}
+
+// blockingWriter blocks writes until gate is closed, signalling entered
+// on first write.
+type blockingWriter struct {
+	once    sync.Once
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+	return len(p), nil
+}
+
+// checkUnlocked checks that Buffer accepts writes while encode is
+// blocked on writing to w.
+func checkUnlocked(t *testing.T, b *Buffer, encode func(w io.Writer) error) {
+	t.Helper()
+	w := newBlockingWriter()
+	done := make(chan error, 1)
+	go func() {
+		done <- encode(w)
+	}()
+	<-w.entered
+	written := make(chan error, 1)
+	go func() {
+		_, err := b.Write(make([]byte, b.SegmentSize()))
+		written <- err
+	}()
+	select {
+	case err := <-written:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Error("write should not be blocked by slow encode")
+	}
+	close(w.gate)
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuffer_EncodeDeltaUnlocked(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 2})
+	if _, err := b.Write([]byte{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	checkUnlocked(t, b, func(w io.Writer) error {
+		return b.EncodeDelta(w, -1)
+	})
+}
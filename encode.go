@@ -0,0 +1,266 @@
+package player
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBadSnapshot indicates invalid or unsupported encoded Buffer state.
+const ErrBadSnapshot Error = "bad snapshot"
+
+// snapshot format constants.
+const (
+	snapshotMagic   = "PLYR"
+	snapshotVersion = 2 // version 1 has no discontinuity marks
+)
+
+// encoder writes big-endian values, keeping first error.
+type encoder struct {
+	w   io.Writer
+	err error
+}
+
+func (e *encoder) int(v int64) {
+	if e.err == nil {
+		e.err = binary.Write(e.w, binary.BigEndian, v)
+	}
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.int(int64(len(v)))
+	if e.err == nil {
+		_, e.err = e.w.Write(v)
+	}
+}
+
+func (e *encoder) time(t time.Time) {
+	if t.IsZero() {
+		e.int(0)
+		return
+	}
+	e.int(t.UnixNano())
+}
+
+// decoder reads values written by encoder, keeping first error.
+type decoder struct {
+	r   io.Reader
+	err error
+}
+
+func (d *decoder) int() int64 {
+	var v int64
+	if d.err == nil {
+		d.err = binary.Read(d.r, binary.BigEndian, &v)
+	}
+	return v
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int()
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 {
+		d.err = ErrBadSnapshot
+		return nil
+	}
+	// not trusting n for allocation, data may be corrupted
+	v, err := io.ReadAll(io.LimitReader(d.r, n))
+	if err == nil && int64(len(v)) != n {
+		err = io.ErrUnexpectedEOF
+	}
+	d.err = err
+	return v
+}
+
+func (d *decoder) time() time.Time {
+	v := d.int()
+	if v == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, v)
+}
+
+// Encode writes full state of Buffer (settings, ids, metadata and
+// segment data) to w in versioned binary format. Cursors and hooks
+// are not encoded.
+func (b *Buffer) Encode(w io.Writer) error {
+	b.l.RLock() // should be unlocked before writing to w
+	s := b.copyState(false)
+	b.l.RUnlock()
+	return s.encode(w)
+}
+
+// copyState returns Buffer with copy of encoded state, so it can be
+// encoded without lock. Trailing partial segment is skipped if full is
+// set. Data of complete segments is never modified, so it is not copied.
+// No locks.
+func (b *Buffer) copyState(full bool) *Buffer {
+	complete := int64(len(b.data)) / b.segment * b.segment
+	s := &Buffer{
+		key:           b.key,
+		segment:       b.segment,
+		maxCount:      b.maxCount,
+		duration:      b.duration,
+		backpressure:  b.backpressure,
+		allowOverflow: b.allowOverflow,
+		firstID:       b.firstID,
+		offset:        b.offset,
+		generation:    b.generation,
+		evicted:       b.evicted,
+		times:         append([]time.Time(nil), b.times...),
+		meta:          make(map[int64][][]byte, len(b.meta)),
+		disc:          make(map[int64]struct{}, len(b.disc)),
+		data:          b.data[:complete:complete],
+	}
+	for id, events := range b.meta {
+		s.meta[id] = events[:len(events):len(events)]
+	}
+	for id := range b.disc {
+		s.disc[id] = struct{}{}
+	}
+	if !full {
+		s.data = append(s.data, b.data[complete:]...)
+	}
+	return s
+}
+
+// encode writes state of Buffer to w. No locks.
+func (b *Buffer) encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	e := &encoder{w: bw}
+	_, e.err = bw.WriteString(snapshotMagic)
+	e.int(snapshotVersion)
+	e.bytes([]byte(b.key))
+	e.int(b.segment)
+	e.int(b.maxCount)
+	e.int(int64(b.duration))
+	e.int(int64(b.backpressure))
+	if b.allowOverflow {
+		e.int(1)
+	} else {
+		e.int(0)
+	}
+	e.int(b.firstID)
+	e.int(b.offset)
+	e.int(b.generation)
+	e.time(b.evicted)
+	e.int(int64(len(b.times)))
+	for _, t := range b.times {
+		e.time(t)
+	}
+	ids := make([]int64, 0, len(b.meta))
+	for id := range b.meta {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	e.int(int64(len(ids)))
+	for _, id := range ids {
+		e.int(id)
+		e.int(int64(len(b.meta[id])))
+		for _, event := range b.meta[id] {
+			e.bytes(event)
+		}
+	}
+	ids = ids[:0]
+	for id := range b.disc {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	e.int(int64(len(ids)))
+	for _, id := range ids {
+		e.int(id)
+	}
+	e.bytes(b.data)
+	if e.err != nil {
+		return errors.Wrap(e.err, "failed to encode")
+	}
+	return errors.Wrap(bw.Flush(), "failed to encode")
+}
+
+// Decode replaces state of Buffer with state read from r, previously
+// written by Encode. Cursors, hooks, tracer and logger of Buffer
+// are preserved.
+func (b *Buffer) Decode(r io.Reader) error {
+	return b.decode(bufio.NewReader(r))
+}
+
+// decode reads state of Buffer from br without reading past its end.
+func (b *Buffer) decode(br *bufio.Reader) error {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != snapshotMagic {
+		return errors.Wrap(ErrBadSnapshot, "bad magic")
+	}
+	d := &decoder{r: br}
+	v := d.int()
+	if d.err == nil && (v < 1 || v > snapshotVersion) {
+		return errors.Wrapf(ErrBadSnapshot, "unsupported version %d", v)
+	}
+	s := &Buffer{}
+	s.key = string(d.bytes())
+	s.segment = d.int()
+	s.maxCount = d.int()
+	s.duration = time.Duration(d.int())
+	s.backpressure = Backpressure(d.int())
+	s.allowOverflow = d.int() == 1
+	s.firstID = d.int()
+	s.offset = d.int()
+	s.generation = d.int()
+	s.evicted = d.time()
+	for n := d.int(); d.err == nil && n > 0; n-- {
+		s.times = append(s.times, d.time())
+	}
+	s.meta = make(map[int64][][]byte)
+	for n := d.int(); d.err == nil && n > 0; n-- {
+		id := d.int()
+		for k := d.int(); d.err == nil && k > 0; k-- {
+			s.meta[id] = append(s.meta[id], d.bytes())
+		}
+	}
+	s.disc = make(map[int64]struct{})
+	if v >= 2 {
+		for n := d.int(); d.err == nil && n > 0; n-- {
+			s.disc[d.int()] = struct{}{}
+		}
+	}
+	s.data = d.bytes()
+	if d.err != nil {
+		return errors.Wrap(d.err, "failed to decode")
+	}
+	if s.segment <= 0 || int64(len(s.times)) != int64(len(s.data))/s.segment {
+		return errors.Wrap(ErrBadSnapshot, "inconsistent state")
+	}
+
+	b.l.Lock()
+	b.key = s.key
+	b.segment = s.segment
+	b.maxCount = s.maxCount
+	b.duration = s.duration
+	b.backpressure = s.backpressure
+	b.allowOverflow = s.allowOverflow
+	b.firstID = s.firstID
+	b.offset = s.offset
+	b.generation = s.generation + 1 // window was reset
+	b.evicted = s.evicted
+	b.times = s.times
+	b.meta = s.meta
+	b.disc = s.disc
+	b.data = s.data
+	// ingest state of replaced window is not relevant anymore
+	b.stalled, b.slated = false, false
+	b.completed = b.evicted
+	if len(b.times) > 0 {
+		b.completed = b.times[len(b.times)-1]
+	}
+	b.count = int64(len(b.data)) / b.segment
+	b.lastID = b.firstID + b.count - 1
+	b.publish()
+	b.broadcast()
+	b.l.Unlock()
+	return nil
+}
@@ -0,0 +1,114 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Encode(t *testing.T) {
+	r := Rand()
+	b := New(Config{
+		Key:     "stream",
+		Count:   4,
+		Segment: 512,
+		Start:   10,
+	})
+	if _, err := io.CopyN(b, r, 512*6+100); err != nil {
+		t.Error(err)
+	}
+	b.WriteMeta([]byte("title"))
+	snapshot := new(bytes.Buffer)
+	if err := b.Encode(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDefault()
+	if err := d.Decode(bytes.NewReader(snapshot.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if d.SegmentSize() != 512 || d.Count() != 4 || d.Size() != b.Size() {
+		t.Error("bad settings", d.SegmentSize(), d.Count(), d.Size())
+	}
+	if d.FirstID() != b.FirstID() || d.LastID() != b.LastID() {
+		t.Error("bad window", d.FirstID(), d.LastID())
+	}
+	if d.Generation() <= b.Generation() {
+		t.Error("generation should be bumped")
+	}
+	expected, got := make([]byte, 512), make([]byte, 512)
+	for id := b.FirstID(); id <= b.LastID(); id++ {
+		if err := b.Get(expected, id); err != nil {
+			t.Error(err)
+		}
+		if err := d.Get(got, id); err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(expected, got) {
+			t.Error("data mismatch", id)
+		}
+	}
+
+	// writing rest of pending segment
+	if _, err := d.Write(make([]byte, 412)); err != nil {
+		t.Error(err)
+	}
+	if meta, err := d.Meta(d.LastID()); err != nil || len(meta) != 1 {
+		t.Error("bad meta", meta, err)
+	}
+}
+
+func TestBuffer_DecodeBad(t *testing.T) {
+	b := NewDefault()
+	if err := b.Decode(bytes.NewReader([]byte("garbage data"))); errors.Cause(err) != ErrBadSnapshot {
+		t.Error(err, "should be", ErrBadSnapshot)
+	}
+	snapshot := new(bytes.Buffer)
+	if err := b.Encode(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	truncated := snapshot.Bytes()[:snapshot.Len()-4]
+	if err := b.Decode(bytes.NewReader(truncated)); err == nil {
+		t.Error("truncated snapshot should fail")
+	}
+}
+
+func TestBuffer_EncodeDiscontinuity(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 2})
+	if _, err := b.Write([]byte{0, 0, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	b.l.Lock()
+	b.addDiscontinuity(1)
+	b.l.Unlock()
+	buf := new(bytes.Buffer)
+	if err := b.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+	d := New(Config{Count: 4, Segment: 2})
+	d.l.Lock()
+	d.addDiscontinuity(0)
+	d.stalled = true
+	d.l.Unlock()
+	if err := d.Decode(buf); err != nil {
+		t.Fatal(err)
+	}
+	for id, want := range []bool{false, true} {
+		if disc, err := d.Discontinuity(int64(id)); err != nil || disc != want {
+			t.Error("bad discontinuity of", id, disc, err)
+		}
+	}
+	if d.Stalled() {
+		t.Error("decoded buffer should not be stalled")
+	}
+}
+
+func TestBuffer_EncodeUnlocked(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 2})
+	if _, err := b.Write([]byte{1, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	checkUnlocked(t, b, b.Encode)
+}
@@ -0,0 +1,96 @@
+package player
+
+import "time"
+
+// EventKind is kind of Buffer lifecycle event.
+type EventKind int
+
+// Possible EventKind values.
+const (
+	// EventSegmentWritten is emitted for every completed segment.
+	EventSegmentWritten EventKind = iota
+	// EventSegmentEvicted is emitted for every evicted segment.
+	EventSegmentEvicted
+	// EventGapDetected is emitted when ingest stalls, see Watch.
+	EventGapDetected
+	// EventStreamClosed is emitted on Shutdown.
+	EventStreamClosed
+	// EventReaderDropped is emitted for every cursor dropped by
+	// backpressure policy.
+	EventReaderDropped
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventSegmentWritten:
+		return "segment_written"
+	case EventSegmentEvicted:
+		return "segment_evicted"
+	case EventGapDetected:
+		return "gap_detected"
+	case EventStreamClosed:
+		return "stream_closed"
+	case EventReaderDropped:
+		return "reader_dropped"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is Buffer lifecycle event.
+type Event struct {
+	Kind EventKind
+	// ID is id of written or evicted segment, or of segment that
+	// follows detected gap; -1 if not applicable.
+	ID int64
+	// Time is completion time of written segment or of the last
+	// segment before gap.
+	Time time.Time
+	// Data is written segment as stored. Data must not be modified.
+	Data []byte
+	// Cursor is dropped cursor.
+	Cursor *Cursor
+}
+
+type subscriber struct {
+	fn func(Event)
+}
+
+// Subscribe registers fn to receive every event of Buffer and returns
+// function that unregisters it. Events are delivered synchronously, in
+// the goroutine that caused them, after Buffer is unlocked, so fn is able
+// to use Buffer but should not block. Unlike OnSegment and OnEvict hooks,
+// subscribers can be added and removed at any time.
+func (b *Buffer) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s := &subscriber{fn: fn}
+	b.el.Lock()
+	b.subscribers = append(b.subscribers[:len(b.subscribers):len(b.subscribers)], s)
+	b.el.Unlock()
+	return func() {
+		b.el.Lock()
+		defer b.el.Unlock()
+		for i, v := range b.subscribers {
+			if v == s {
+				b.subscribers = append(b.subscribers[:i:i], b.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// subscribed reports whether Buffer has event subscribers.
+func (b *Buffer) subscribed() bool {
+	b.el.Lock()
+	defer b.el.Unlock()
+	return len(b.subscribers) > 0
+}
+
+// emit delivers event to subscribers. Should be called without lock.
+func (b *Buffer) emit(e Event) {
+	b.el.Lock()
+	subscribers := b.subscribers // never modified in place
+	b.el.Unlock()
+	for _, s := range subscribers {
+		s.fn(e)
+	}
+}
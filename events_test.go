@@ -0,0 +1,79 @@
+package player
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBuffer_Subscribe(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 2, Segment: 2, Clock: c, Backpressure: BackpressureDrop})
+	var (
+		mux    sync.Mutex
+		events []Event
+	)
+	unsubscribe := b.Subscribe(func(e Event) {
+		mux.Lock()
+		events = append(events, e)
+		mux.Unlock()
+	})
+	slow := b.NewCursor(0)
+	if _, err := b.Write([]byte{0, 0, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte{2, 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, time.Second)
+	waitTimers(t, c, 1)
+	c.Advance(time.Second)
+	deadline := time.Now().Add(time.Second)
+	for !b.Stalled() {
+		if time.Now().After(deadline) {
+			t.Fatal("stall should be detected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	unsubscribe()
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+	want := []struct {
+		kind EventKind
+		id   int64
+	}{
+		{EventSegmentWritten, 0},
+		{EventSegmentWritten, 1},
+		{EventReaderDropped, -1},
+		{EventSegmentEvicted, 0},
+		{EventSegmentWritten, 2},
+		{EventGapDetected, 3},
+		{EventStreamClosed, -1},
+	}
+	if len(events) != len(want) {
+		t.Fatal("bad events", events)
+	}
+	for i, w := range want {
+		if e := events[i]; e.Kind != w.kind || e.ID != w.id {
+			t.Errorf("event %d: got %s %d, want %s %d", i, e.Kind, e.ID, w.kind, w.id)
+		}
+	}
+	if events[2].Cursor != slow {
+		t.Error("dropped cursor should be reported")
+	}
+	if events[1].Data[0] != 1 {
+		t.Error("bad segment data", events[1].Data)
+	}
+}
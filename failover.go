@@ -0,0 +1,146 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Coordinator decides which node is primary, e.g. via leader election.
+type Coordinator interface {
+	// Wait blocks until this node is elected as primary or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// Replicate writes snapshot of Buffer to w, followed by every new segment
+// framed with its id and metadata, until ctx is done or write fails.
+// Trailing partial segment is not included in snapshot and is replicated
+// when completed, so stream consumed by Follow has no gaps or
+// duplicates. If w falls behind the window, replication fails with
+// ErrMiss instead of skipping segments, and replica should be re-synced.
+// Segments are replicated as stored, so replica usually has no
+// Transforms.
+func (b *Buffer) Replicate(ctx context.Context, w io.Writer) error {
+	b.l.Lock()
+	s := b.copyState(true)
+	c := b.newCursor(b.nextID()) // keeps backpressure aware of replica
+	b.l.Unlock()
+	defer c.Close()
+
+	bw := bufio.NewWriter(w)
+	if err := s.encode(bw); err != nil {
+		return errors.Wrap(err, "failed to replicate")
+	}
+	e := &encoder{w: bw}
+	for id := s.firstID + int64(len(s.data))/s.segment; ; id++ {
+		if err := bw.Flush(); err != nil {
+			return errors.Wrap(err, "failed to replicate")
+		}
+		if err := b.WaitForID(ctx, id); err != nil {
+			return errors.Wrap(err, "replication stopped")
+		}
+		b.l.RLock()
+		err := b.acquireID(id)
+		var seg []byte
+		var meta [][]byte
+		if err == nil {
+			// data of complete segment and its events are never modified
+			seg = b.data[b.segment*(id-b.firstID) : b.segment*(id-b.firstID+1)]
+			meta = b.meta[id]
+		}
+		b.l.RUnlock()
+		if err != nil {
+			return errors.Wrap(err, "replica fell behind")
+		}
+		e.int(id)
+		e.int(int64(len(meta)))
+		for _, event := range meta {
+			e.bytes(event)
+		}
+		e.bytes(seg)
+		if e.err != nil {
+			return errors.Wrap(e.err, "failed to replicate")
+		}
+		c.SetID(id + 1)
+	}
+}
+
+// Follow replaces state of Buffer with snapshot from r, written by
+// Replicate, and applies new segments from r by WriteSegment until it
+// ends or ctx is done.
+func (b *Buffer) Follow(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReader(r)
+	if err := b.decode(br); err != nil {
+		return errors.Wrap(err, "failed to follow")
+	}
+	d := &decoder{r: br}
+	for {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "follow stopped")
+		}
+		if _, err := br.Peek(1); err == io.EOF {
+			return nil
+		}
+		id := d.int()
+		var events [][]byte
+		for n := d.int(); d.err == nil && n > 0; n-- {
+			events = append(events, d.bytes())
+		}
+		seg := d.bytes()
+		if d.err != nil {
+			return errors.Wrap(d.err, "failed to read segment")
+		}
+		if err := b.WriteSegment(ctx, id, seg); err != nil {
+			return errors.Wrap(err, "failed to follow")
+		}
+		b.l.Lock()
+		if _, ok := b.meta[id]; !ok && id >= b.firstID {
+			for _, event := range events {
+				b.addMeta(id, event)
+			}
+		}
+		b.l.Unlock()
+	}
+}
+
+// Standby mirrors primary via Follow until c elects this node, then
+// closes r and returns nil. Segment ids continue from the last mirrored
+// segment, so after Standby returns the caller can start writing ingest
+// to Buffer without clients observing gaps or duplicate ids.
+func (b *Buffer) Standby(ctx context.Context, r io.ReadCloser, c Coordinator) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Follow(ctx, r)
+	}()
+	elected := make(chan error, 1)
+	go func() {
+		elected <- c.Wait(ctx)
+	}()
+
+	select {
+	case err := <-elected:
+		if err != nil {
+			return errors.Wrap(err, "failed to wait for election")
+		}
+	case err := <-done:
+		if err == nil {
+			// primary ended replication stream, waiting for election
+			err = <-elected
+		}
+		if err != nil {
+			return errors.Wrap(err, "standby failed")
+		}
+		return nil
+	}
+	// promoted, stopping replication
+	cancel()
+	if err := r.Close(); err != nil {
+		return errors.Wrap(err, "failed to close replication stream")
+	}
+	<-done
+	return nil
+}
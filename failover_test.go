@@ -0,0 +1,118 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// chanCoordinator elects node when channel is closed.
+type chanCoordinator chan struct{}
+
+func (c chanCoordinator) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c:
+		return nil
+	}
+}
+
+func TestBuffer_Standby(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	primary := New(Config{
+		Count:   4,
+		Segment: 512,
+		Start:   10,
+	})
+	if _, err := io.CopyN(primary, Rand(), 512*2+100); err != nil {
+		t.Error(err)
+	}
+	r, w := io.Pipe()
+	go primary.Replicate(ctx, w)
+
+	standby := NewDefault()
+	elect := make(chanCoordinator)
+	done := make(chan error)
+	go func() {
+		done <- standby.Standby(ctx, r, elect)
+	}()
+	// completing partial segment and writing one more
+	if _, err := io.CopyN(primary, Rand(), 512*2-100); err != nil {
+		t.Error(err)
+	}
+	for standby.LastID() != primary.LastID() {
+		time.Sleep(time.Millisecond)
+	}
+	close(elect)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	expected, got := make([]byte, 512), make([]byte, 512)
+	for id := primary.FirstID(); id <= primary.LastID(); id++ {
+		if err := primary.Get(expected, id); err != nil {
+			t.Error(err)
+		}
+		if err := standby.Get(got, id); err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(expected, got) {
+			t.Error("data mismatch", id)
+		}
+	}
+	// promoted standby continues numbering
+	if _, err := standby.Write(got); err != nil {
+		t.Error(err)
+	}
+	if standby.LastID() != primary.LastID()+1 {
+		t.Error("bad last id after promotion", standby.LastID())
+	}
+}
+
+// gateWriter blocks second and following writes until gate is closed,
+// signalling entered on second write.
+type gateWriter struct {
+	writes  int
+	entered chan struct{}
+	gate    chan struct{}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	if w.writes++; w.writes == 2 {
+		close(w.entered)
+		<-w.gate
+	}
+	return len(p), nil
+}
+
+func TestBuffer_ReplicateBehind(t *testing.T) {
+	primary := New(Config{Count: 2, Segment: 2})
+	w := &gateWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+	done := make(chan error, 1)
+	go func() {
+		done <- primary.Replicate(context.Background(), w)
+	}()
+	for primary.CursorStats().Count == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := primary.Write([]byte{0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	<-w.entered
+	// replica is blocked on segment 0 while segment 1 is evicted
+	for i := 1; i < 4; i++ {
+		if _, err := primary.Write([]byte{byte(i), byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(w.gate)
+	if err := <-done; errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
@@ -0,0 +1,50 @@
+package player
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrBadGroup indicates invalid size of parity group.
+const ErrBadGroup Error = "bad parity group"
+
+// Parity returns XOR parity of k segments starting from id first, as
+// stored. Parity can be sent over side channel with the segments, so
+// receiver can restore any single missing segment of the group by
+// RecoverSegment. Unlike Reed-Solomon codes, XOR parity recovers only
+// one lost segment per group, so k should be chosen for expected loss.
+// Returns ErrBadGroup if k is not positive.
+func (b *Buffer) Parity(first, k int64) ([]byte, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if k <= 0 {
+		return nil, errors.Wrap(ErrBadGroup, "bad group size")
+	}
+	for _, id := range []int64{first, first + k - 1} {
+		if err := b.acquireID(id); err != nil {
+			return nil, errors.Wrap(err, "bad id")
+		}
+	}
+	parity := make([]byte, b.segment)
+	start := b.segment * (first - b.firstID)
+	data := b.data[start : start+b.segment*k]
+	for i := range data {
+		parity[int64(i)%b.segment] ^= data[i]
+	}
+	return parity, nil
+}
+
+// RecoverSegment restores single missing segment of group from parity
+// returned by Buffer.Parity and the other segments of group.
+func RecoverSegment(parity []byte, present [][]byte) ([]byte, error) {
+	seg := make([]byte, len(parity))
+	copy(seg, parity)
+	for _, p := range present {
+		if len(p) != len(seg) {
+			return nil, errors.Wrap(ErrBadSegment, "failed to recover")
+		}
+		for i := range p {
+			seg[i] ^= p[i]
+		}
+	}
+	return seg, nil
+}
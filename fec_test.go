@@ -0,0 +1,44 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Parity(t *testing.T) {
+	b := New(Config{Count: 8, Segment: 512})
+	if _, err := io.CopyN(b, Rand(), 512*4); err != nil {
+		t.Fatal(err)
+	}
+	parity, err := b.Parity(0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments := make([][]byte, 4)
+	for i := range segments {
+		segments[i] = make([]byte, 512)
+		if err := b.Get(segments[i], int64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// losing segment 2
+	seg, err := RecoverSegment(parity, [][]byte{segments[0], segments[1], segments[3]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(seg, segments[2]) {
+		t.Error("segment should be recovered")
+	}
+	if _, err := b.Parity(2, 4); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if _, err := b.Parity(0, 0); errors.Cause(err) != ErrBadGroup {
+		t.Error(err, "should be", ErrBadGroup)
+	}
+	if _, err := RecoverSegment(parity, [][]byte{{1}}); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+}
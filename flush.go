@@ -0,0 +1,59 @@
+package player
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Partial returns length of trailing partial segment, i.e. bytes that
+// are written but not readable yet, including bytes staged by Coalesce.
+// Size includes them only if they are not staged, LastID does not.
+func (b *Buffer) Partial() int64 {
+	staged := b.staging()
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return int64(len(b.data))%b.segment + staged
+}
+
+// Flush completes trailing partial segment by padding it with pad byte
+// (e.g. 0xFF), so it becomes readable, and returns count of padding
+// bytes. Metadata attached to partial segment stays attached to it.
+// Padding is stored as is, without Transforms and backpressure.
+func (b *Buffer) Flush(pad byte) (int64, error) {
+	if b.coalesce {
+		b.sl.Lock()
+		err := b.drain(context.Background(), true)
+		b.sl.Unlock()
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to flush")
+		}
+	}
+	b.l.Lock()
+	if b.closed {
+		b.l.Unlock()
+		return 0, errors.Wrap(ErrClosed, "failed to flush")
+	}
+	partial := int64(len(b.data)) % b.segment
+	if partial == 0 {
+		b.l.Unlock()
+		return 0, nil
+	}
+	n := b.segment - partial
+	if b.overflowsID(n) {
+		b.l.Unlock()
+		return 0, errors.Wrap(ErrIDOverflow, "failed to flush")
+	}
+	next := b.nextID()
+	padding := bytes.Repeat([]byte{pad}, int(n))
+	from, to := b.store(padding, b.clock.Now())
+	completed := b.completedSince(next)
+	b.broadcast()
+	b.tl.Lock()
+	b.l.Unlock()
+	b.tee(padding)
+	b.onEvict(from, to)
+	b.onSegment(completed)
+	return n, nil
+}
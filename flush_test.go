@@ -0,0 +1,37 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuffer_Flush(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4})
+	if n, err := b.Flush(0xFF); err != nil || n != 0 {
+		t.Error("empty buffer should not be padded", n, err)
+	}
+	if _, err := b.Write([]byte{1, 1, 1, 1, 2}); err != nil {
+		t.Fatal(err)
+	}
+	id := b.WriteMeta([]byte("end"))
+	if b.Partial() != 1 {
+		t.Error("bad partial", b.Partial())
+	}
+	n, err := b.Flush(0xFF)
+	if err != nil || n != 3 {
+		t.Fatal("bad flush", n, err)
+	}
+	if b.Partial() != 0 || b.LastID() != 1 {
+		t.Error("partial segment should be completed", b.Partial(), b.LastID())
+	}
+	buf := make([]byte, 4)
+	if err := b.Get(buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{2, 0xFF, 0xFF, 0xFF}) {
+		t.Error("bad segment", buf)
+	}
+	if meta, err := b.Meta(id); err != nil || id != 1 || len(meta) != 1 {
+		t.Error("meta should be attached to flushed segment", id, meta, err)
+	}
+}
@@ -0,0 +1,128 @@
+package player
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TailFile writes data of growing file at path to Buffer, e.g. output of
+// external encoder, checking for new data every interval until ctx is
+// done. Reading starts from the beginning of file.
+func (b *Buffer) TailFile(ctx context.Context, path string, interval time.Duration) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrap(err, "failed to tail")
+	}
+	defer f.Close()
+	for {
+		if _, err := b.ReadFromContext(ctx, f); err != nil {
+			return errors.Wrap(err, "failed to tail")
+		}
+		if err := sleepUntil(ctx, b.clock, b.clock.Now().Add(interval)); err != nil {
+			return errors.Wrap(err, "tail stopped")
+		}
+	}
+}
+
+// segmentName matches the last number in segment file name without
+// extension, which is used as segment id, e.g. 42 in "stream-42.m4s".
+var segmentName = regexp.MustCompile(`(\d+)\D*$`)
+
+// segmentFile is segment file found in watched directory.
+type segmentFile struct {
+	id   int64
+	path string
+}
+
+// WatchDir imports segment files written to dir by external packager,
+// checking for new files every interval until ctx is done. Segment id is
+// parsed from the last number in file name without extension, and files
+// are imported by WriteSegment in order of ids, starting from the next
+// id of Buffer, so Config.Start should be set to id of the first
+// imported file. File is imported when it has the size of segment, and
+// ErrBadSegment is returned for bigger files. Import waits for missing
+// ids.
+func (b *Buffer) WatchDir(ctx context.Context, dir string, interval time.Duration) error {
+	for {
+		if err := b.importDir(ctx, dir); err != nil {
+			return err
+		}
+		if err := sleepUntil(ctx, b.clock, b.clock.Now().Add(interval)); err != nil {
+			return errors.Wrap(err, "watch stopped")
+		}
+	}
+}
+
+// importDir imports consecutive complete segment files of dir, starting
+// from the next id of Buffer.
+func (b *Buffer) importDir(ctx context.Context, dir string) error {
+	files, err := segmentFiles(dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to list segments")
+	}
+	for _, f := range files {
+		next := b.next()
+		if f.id < next {
+			continue
+		}
+		if f.id > next {
+			return nil
+		}
+		seg, err := os.ReadFile(f.path)
+		if err != nil {
+			return errors.Wrap(err, "failed to import")
+		}
+		size := b.SegmentSize()
+		if int64(len(seg)) < size {
+			// still written by packager
+			return nil
+		}
+		if int64(len(seg)) > size {
+			return errors.Wrapf(ErrBadSegment, "failed to import %s", f.path)
+		}
+		if err := b.WriteSegment(ctx, f.id, seg); err != nil {
+			return errors.Wrap(err, "failed to import")
+		}
+	}
+	return nil
+}
+
+// next returns id of the next segment to write.
+func (b *Buffer) next() int64 {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.nextID()
+}
+
+// segmentFiles returns files of dir with ids in names, sorted by id.
+func segmentFiles(dir string) ([]segmentFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []segmentFile
+	for _, e := range entries {
+		name := e.Name()
+		m := segmentName.FindStringSubmatch(strings.TrimSuffix(name, filepath.Ext(name)))
+		if e.IsDir() || m == nil {
+			continue
+		}
+		id, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, segmentFile{id: id, path: filepath.Join(dir, name)})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].id < files[j].id
+	})
+	return files, nil
+}
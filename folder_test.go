@@ -0,0 +1,114 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_TailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.ts")
+	if err := os.WriteFile(path, []byte{0, 1, 2}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 2, Clock: c})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.TailFile(ctx, path, time.Second)
+	}()
+	waitTimers(t, c, 1)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte{3, 4, 5}); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	c.Advance(time.Second)
+	if err := b.WaitForID(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := b.ReadLast(buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{0, 1, 2, 3, 4, 5}) {
+		t.Error("bad data", buf.Bytes())
+	}
+}
+
+func TestBuffer_WatchDir(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("seg-9.ts", []byte{9, 9})
+	write("seg-10.ts", []byte{10, 10})
+	write("seg-12.ts", []byte{12, 12})
+	write("seg-11.ts", []byte{11}) // still written
+	write("playlist.m3u8", nil)
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 2, Start: 10, Clock: c})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.WatchDir(ctx, dir, time.Second)
+	}()
+	waitTimers(t, c, 1)
+	if ids := b.LatestIDs(4); len(ids) != 1 || ids[0] != 10 {
+		t.Fatal("bad ids", ids)
+	}
+	write("seg-11.ts", []byte{11, 11})
+	c.Advance(time.Second)
+	if err := b.WaitForID(ctx, 12); err != nil {
+		t.Fatal(err)
+	}
+	write("seg-13.ts", []byte{13, 13, 13})
+	waitTimers(t, c, 1)
+	c.Advance(time.Second)
+	if err := <-done; errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+	cancel()
+	buf := new(bytes.Buffer)
+	if _, err := b.ReadLast(buf, 4); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{10, 10, 11, 11, 12, 12}) {
+		t.Error("bad data", buf.Bytes())
+	}
+}
+
+func TestSegmentFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"seg-42.m4s", "stream-7.mp4", "init.mp4", "playlist.m3u8"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := segmentFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].id != 7 || files[1].id != 42 {
+		t.Fatal("bad files", files)
+	}
+	if filepath.Base(files[1].path) != "seg-42.m4s" {
+		t.Error("bad path", files[1].path)
+	}
+}
@@ -0,0 +1,56 @@
+package player
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StreamHealth is health of single stream reported by ReadinessHandler.
+type StreamHealth struct {
+	Stalled bool  `json:"stalled"`
+	Closed  bool  `json:"closed"`
+	LastID  int64 `json:"last_id"`
+	Cursors int   `json:"cursors"`
+}
+
+// LivenessHandler is http.Handler for liveness probe, e.g. /healthz. It
+// responds with status 200 while process serves requests: state of
+// streams is reported by ReadinessHandler, so stalled upstream does not
+// restart the process.
+type LivenessHandler struct{}
+
+func (LivenessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok\n"))
+}
+
+// ReadinessHandler is http.Handler for readiness probe, e.g. /readyz. It
+// responds with health of every stream as JSON, with status 503 if any
+// stream has stalled ingest (as detected by Watch) or is shut down, so
+// load balancer drains the instance.
+type ReadinessHandler struct {
+	Streams map[string]*Buffer
+}
+
+func (h ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := http.StatusOK
+	report := make(map[string]StreamHealth, len(h.Streams))
+	for key, b := range h.Streams {
+		b.l.RLock()
+		s := StreamHealth{
+			Stalled: b.stalled,
+			Closed:  b.closed,
+			LastID:  b.nextID() - 1,
+			Cursors: len(b.cursors),
+		}
+		b.l.RUnlock()
+		if s.Stalled || s.Closed {
+			status = http.StatusServiceUnavailable
+		}
+		report[key] = s
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,45 @@
+package player
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	live := New(Config{Count: 2, Segment: 4})
+	ended := New(Config{Count: 2, Segment: 4})
+	if _, err := live.Write([]byte{1, 1, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	h := ReadinessHandler{Streams: map[string]*Buffer{"live": live, "ended": ended}}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Error("bad status", w.Code)
+	}
+	var report map[string]StreamHealth
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report["live"].LastID != 0 || report["ended"].LastID != -1 {
+		t.Errorf("bad report %+v", report)
+	}
+
+	if err := ended.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Error("bad status", w.Code)
+	}
+	// stream state does not affect liveness
+	w = httptest.NewRecorder()
+	LivenessHandler{}.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Error("bad liveness status", w.Code)
+	}
+}
@@ -0,0 +1,107 @@
+package player
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ErrOutOfOrder means that segment can't be written with provided id,
+// because previous segments are not written yet.
+const ErrOutOfOrder Error = "segment is out of order"
+
+// errDuplicate means that segment with provided id is already written.
+const errDuplicate Error = "duplicate segment"
+
+// WriteSegment writes whole segment with provided id. Write of already
+// written id is ignored, so retries are idempotent. Returns ErrOutOfOrder
+// if id is not next to write or there is partially written segment, and
+// ErrBadSegment if seg has wrong size.
+func (b *Buffer) WriteSegment(ctx context.Context, id int64, seg []byte) error {
+	if id < 0 {
+		return errors.Wrap(ErrMiss, "bad id")
+	}
+	// staged bytes are part of partial segment
+	b.sl.Lock()
+	defer b.sl.Unlock()
+	if len(b.staged) > 0 {
+		return errors.Wrap(ErrOutOfOrder, "failed to write")
+	}
+	_, err := b.write(ctx, id, seg)
+	return err
+}
+
+// checkSegment checks that buf can be written as segment with provided
+// id. Returns errDuplicate if it is already written. No locks.
+func (b *Buffer) checkSegment(id int64, buf []byte) error {
+	next := b.nextID()
+	switch {
+	case int64(len(buf)) != b.segment:
+		return ErrBadSegment
+	case id < next:
+		return errDuplicate
+	case id > next || int64(len(b.data))%b.segment != 0:
+		return ErrOutOfOrder
+	}
+	return nil
+}
+
+// IngestHandler is http.Handler for encoders that push media over HTTP.
+// POST appends request body to Buffer as continuous stream, and PUT
+// writes body as whole segment with id from the last path element,
+// e.g. "PUT /ingest/42", via WriteSegment.
+type IngestHandler struct {
+	Buffer *Buffer
+	// Authorize is optional check of request, e.g. of stream key.
+	Authorize func(r *http.Request) error
+}
+
+func (h IngestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Authorize != nil {
+		if err := h.Authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+	}
+	switch r.Method {
+	case http.MethodPost:
+		if _, err := h.Buffer.ReadFromContext(r.Context(), r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	case http.MethodPut:
+		id, err := strconv.ParseInt(path.Base(r.URL.Path), 10, 64)
+		if err != nil {
+			http.Error(w, "bad id", http.StatusBadRequest)
+			return
+		}
+		// reading at most one byte more than segment to detect bad size
+		seg, err := io.ReadAll(io.LimitReader(r.Body, h.Buffer.SegmentSize()+1))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = h.Buffer.WriteSegment(r.Context(), id, seg)
+		switch errors.Cause(err) {
+		case nil:
+		case ErrOutOfOrder:
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		case ErrBadSegment, ErrMiss:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
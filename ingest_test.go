@@ -0,0 +1,79 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_WriteSegment(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	ctx := context.Background()
+	if err := b.WriteSegment(ctx, 0, []byte{0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	// retry should be ignored
+	if err := b.WriteSegment(ctx, 0, []byte{1, 1, 1, 1}); err != nil {
+		t.Error(err)
+	}
+	if err := b.WriteSegment(ctx, 2, []byte{2, 2, 2, 2}); errors.Cause(err) != ErrOutOfOrder {
+		t.Error(err, "should be", ErrOutOfOrder)
+	}
+	if err := b.WriteSegment(ctx, 1, []byte{1, 1}); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+	if _, err := b.Write([]byte{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.WriteSegment(ctx, 1, []byte{1, 1, 1, 1}); errors.Cause(err) != ErrOutOfOrder {
+		t.Error(err, "should be", ErrOutOfOrder)
+	}
+	buf := make([]byte, 4)
+	if err := b.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{0, 0, 0, 0}) {
+		t.Error("segment should not be overwritten", buf)
+	}
+}
+
+func TestIngestHandler(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	h := IngestHandler{
+		Buffer: b,
+		Authorize: func(r *http.Request) error {
+			if r.URL.Query().Get("key") != "secret" {
+				return errors.New("bad stream key")
+			}
+			return nil
+		},
+	}
+	for _, tt := range []struct {
+		method, target string
+		body           []byte
+		code           int
+	}{
+		{http.MethodPost, "/ingest", []byte{0, 0, 0, 0}, http.StatusForbidden},
+		{http.MethodPost, "/ingest?key=secret", []byte{0, 0, 0, 0, 1, 1}, http.StatusNoContent},
+		{http.MethodPost, "/ingest?key=secret", []byte{1, 1}, http.StatusNoContent},
+		{http.MethodPut, "/ingest/2?key=secret", []byte{2, 2, 2, 2}, http.StatusNoContent},
+		{http.MethodPut, "/ingest/2?key=secret", []byte{2, 2, 2, 2}, http.StatusNoContent},
+		{http.MethodPut, "/ingest/4?key=secret", []byte{4, 4, 4, 4}, http.StatusConflict},
+		{http.MethodPut, "/ingest/3?key=secret", []byte{3, 3, 3, 3, 3}, http.StatusBadRequest},
+		{http.MethodPut, "/ingest/x?key=secret", []byte{3, 3, 3, 3}, http.StatusBadRequest},
+		{http.MethodGet, "/ingest?key=secret", nil, http.StatusMethodNotAllowed},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, bytes.NewReader(tt.body)))
+		if w.Code != tt.code {
+			t.Error(tt.method, tt.target, "bad status", w.Code, "should be", tt.code)
+		}
+	}
+	if _, last, _ := b.Window(); last != 2 {
+		t.Error("bad last id", last)
+	}
+}
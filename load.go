@@ -0,0 +1,75 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// MetaFile is name of metadata sidecar file in directory loaded by
+// LoadDir. It contains JSON objects with segment id and base64 encoded
+// event, one per line, e.g. {"id":42,"event":"dGl0bGU="}.
+const MetaFile = "meta.jsonl"
+
+// metaLine is line of metadata sidecar file.
+type metaLine struct {
+	ID    int64  `json:"id"`
+	Event []byte `json:"event"`
+}
+
+// LoadFrom populates Buffer from r, which is either snapshot written by
+// Encode, replacing state of Buffer together with metadata, or ingest
+// recording written by Recorder, which is written to Buffer without
+// delays.
+func (b *Buffer) LoadFrom(r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(len(snapshotMagic))
+	if err != nil {
+		return errors.Wrap(err, "failed to read magic")
+	}
+	switch string(magic) {
+	case snapshotMagic:
+		return b.decode(br)
+	case recordingMagic:
+		return Replay(context.Background(), br, b, 0)
+	default:
+		return errors.Wrap(ErrBadRecording, "unknown format")
+	}
+}
+
+// LoadDir populates Buffer from segment files in dir, named as for
+// WatchDir, and from metadata sidecar file MetaFile, if present.
+// Consecutive segments are loaded starting from the next id of Buffer,
+// so Config.Start should be set to id of the first segment file.
+func (b *Buffer) LoadDir(dir string) error {
+	if err := b.importDir(context.Background(), dir); err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(dir, MetaFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open metadata")
+	}
+	defer f.Close()
+	d := json.NewDecoder(f)
+	for {
+		var line metaLine
+		if err := d.Decode(&line); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrap(err, "failed to read metadata")
+		}
+		b.l.Lock()
+		if line.ID >= b.firstID && line.ID < b.nextID() {
+			b.addMeta(line.ID, line.Event)
+		}
+		b.l.Unlock()
+	}
+}
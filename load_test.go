@@ -0,0 +1,80 @@
+package player
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_LoadFrom(t *testing.T) {
+	src := New(Config{Count: 4, Segment: 2})
+	src.WriteMeta([]byte("title"))
+	if _, err := src.Write([]byte{1, 1, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	snapshot := new(bytes.Buffer)
+	if err := src.Encode(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	b := New(Config{Count: 4, Segment: 2})
+	if err := b.LoadFrom(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if meta, err := b.Meta(0); err != nil || len(meta) != 1 || string(meta[0]) != "title" {
+		t.Error("bad metadata", meta, err)
+	}
+
+	recording := new(bytes.Buffer)
+	rec, err := NewRecorder(New(Config{Count: 4, Segment: 2}), recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][]byte{{1, 1, 2}, {2, 3, 3}} {
+		if _, err := rec.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b = New(Config{Count: 4, Segment: 2})
+	if err := b.LoadFrom(recording); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := b.ReadLast(buf, 4); err != nil || !bytes.Equal(buf.Bytes(), []byte{1, 1, 2, 2, 3, 3}) {
+		t.Error("bad data", buf.Bytes(), err)
+	}
+
+	if err := b.LoadFrom(bytes.NewReader([]byte("JUNKJUNK"))); errors.Cause(err) != ErrBadRecording {
+		t.Error(err, "should be", ErrBadRecording)
+	}
+}
+
+func TestBuffer_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"seg-5.ts": "aa",
+		"seg-6.ts": "bb",
+		MetaFile:   `{"id":4,"event":"b2xk"}` + "\n" + `{"id":6,"event":"dGl0bGU="}` + "\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := New(Config{Count: 4, Segment: 2, Start: 5})
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := b.ReadLast(buf, 4); err != nil || buf.String() != "aabb" {
+		t.Error("bad data", buf.String(), err)
+	}
+	if meta, err := b.Meta(6); err != nil || len(meta) != 1 || string(meta[0]) != "title" {
+		t.Error("bad metadata", meta, err)
+	}
+	if _, err := b.Meta(4); err == nil {
+		t.Error("metadata of segment before window should be skipped")
+	}
+}
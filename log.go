@@ -0,0 +1,12 @@
+package player
+
+// Logger is minimal logging interface for warnings, compatible
+// with *log.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// noopLogger is default Logger that discards everything.
+type noopLogger struct{}
+
+func (noopLogger) Printf(format string, v ...interface{}) {}
@@ -0,0 +1,34 @@
+package player
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestBuffer_Logger(t *testing.T) {
+	out := new(bytes.Buffer)
+	b := New(Config{
+		Key:           "stream",
+		Count:         2,
+		Segment:       512,
+		AllowOverflow: true,
+		Backpressure:  BackpressureDrop,
+		Logger:        log.New(out, "", 0),
+	})
+	b.NewCursor(0)
+	buf := make([]byte, 512*3)
+	if _, err := b.Write(buf[:512*2]); err != nil {
+		t.Error(err)
+	}
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if !strings.Contains(out.String(), "stream: overflow write of 1536 bytes") {
+		t.Error("overflow write should be logged:", out.String())
+	}
+	if !strings.Contains(out.String(), "stream: dropped 1 slow cursors") {
+		t.Error("dropped cursor should be logged:", out.String())
+	}
+}
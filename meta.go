@@ -0,0 +1,66 @@
+package player
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// nextID returns id of segment that is currently being written.
+// No locks.
+func (b *Buffer) nextID() int64 {
+	return b.firstID + int64(len(b.data))/b.segment
+}
+
+// WriteMeta attaches timed metadata event (e.g. song title or ID3 payload)
+// to the segment that is currently being written, so readers can consume
+// it in sync with media. Returns id of that segment.
+func (b *Buffer) WriteMeta(data []byte) int64 {
+	event := make([]byte, len(data))
+	copy(event, data)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+	id := b.nextID()
+	b.addMeta(id, event)
+	return id
+}
+
+// WriteMetaAt attaches metadata event to the segment that contains
+// timestamp t, or to the segment that is currently being written if t
+// is later than the last completed segment. Returns id of that segment.
+func (b *Buffer) WriteMetaAt(t time.Time, data []byte) (int64, error) {
+	event := make([]byte, len(data))
+	copy(event, data)
+
+	b.l.Lock()
+	defer b.l.Unlock()
+	id := b.nextID()
+	if len(b.times) > 0 && !t.After(b.times[len(b.times)-1]) {
+		var err error
+		if id, err = b.idAt(t); err != nil {
+			return 0, err
+		}
+	}
+	b.addMeta(id, event)
+	return id, nil
+}
+
+// addMeta attaches event to segment with provided id. No locks.
+func (b *Buffer) addMeta(id int64, event []byte) {
+	if b.meta == nil {
+		b.meta = make(map[int64][][]byte)
+	}
+	b.meta[id] = append(b.meta[id], event)
+}
+
+// Meta returns metadata events attached to segment with provided id
+// in order of writing. Returned slices must not be modified.
+func (b *Buffer) Meta(id int64) ([][]byte, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if err := b.acquireID(id); err != nil {
+		return nil, errors.Wrap(err, "bad id")
+	}
+	return b.meta[id], nil
+}
@@ -0,0 +1,47 @@
+package player
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Meta(t *testing.T) {
+	b := New(Config{
+		Count:   2,
+		Segment: 512,
+	})
+	if id := b.WriteMeta([]byte("title: foo")); id != 0 {
+		t.Error("bad id", id)
+	}
+	buf := make([]byte, 512)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if id := b.WriteMeta([]byte("title: bar")); id != 1 {
+		t.Error("bad id", id)
+	}
+	meta, err := b.Meta(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(meta) != 1 || string(meta[0]) != "title: foo" {
+		t.Error("bad meta", meta)
+	}
+	if _, err := b.Meta(1); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+
+	// evicting segment 0
+	for i := 0; i < 2; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	if _, err := b.Meta(0); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if meta, err = b.Meta(1); err != nil || len(meta) != 1 {
+		t.Error(err, meta)
+	}
+}
@@ -0,0 +1,54 @@
+package player
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// MultiBuffer returns writer that writes the same stream to all buffers,
+// e.g. short low-latency window and long DVR window with different
+// segment sizes. Segment boundaries are derived from byte count, so each
+// Buffer segments the stream by itself.
+//
+// Unlike io.MultiWriter, failed write to one Buffer does not prevent
+// write to others, and first error is returned. Writes are split to fit
+// into the smallest window, so Buffer with short window does not reject
+// writes that fit into others. Write to Buffer with empty window fails
+// with ErrTooLargeWrite.
+func MultiBuffer(buffers ...*Buffer) io.Writer {
+	return multiBuffer(append([]*Buffer(nil), buffers...))
+}
+
+type multiBuffer []*Buffer
+
+func (m multiBuffer) Write(p []byte) (int, error) {
+	if len(m) == 0 {
+		return len(p), nil
+	}
+	var written int
+	for len(p) > 0 {
+		chunk := int64(len(p))
+		for _, b := range m {
+			size := b.SegmentSize() * b.Count()
+			if size <= 0 {
+				return written, errors.Wrapf(ErrTooLargeWrite, "window of %q is empty", b.key)
+			}
+			if size < chunk {
+				chunk = size
+			}
+		}
+		var first error
+		for _, b := range m {
+			if _, err := b.Write(p[:chunk]); err != nil && first == nil {
+				first = errors.Wrapf(err, "failed to write to %q", b.key)
+			}
+		}
+		if first != nil {
+			return written, first
+		}
+		written += int(chunk)
+		p = p[chunk:]
+	}
+	return written, nil
+}
@@ -0,0 +1,57 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMultiBuffer(t *testing.T) {
+	short := New(Config{Count: 2, Segment: 2})
+	long := New(Config{Count: 4, Segment: 4})
+	w := MultiBuffer(short, long)
+	// larger than short window
+	if n, err := w.Write([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8}); err != nil || n != 9 {
+		t.Fatal(n, err)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := long.ReadLast(buf, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{0, 1, 2, 3, 4, 5, 6, 7}) {
+		t.Error("bad long window", buf.Bytes())
+	}
+	buf.Reset()
+	if _, err := short.ReadLast(buf, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{6, 7}) {
+		t.Error("bad short window", buf.Bytes())
+	}
+}
+
+func TestMultiBuffer_Error(t *testing.T) {
+	closed := New(Config{Count: 2, Segment: 2})
+	if err := closed.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b := New(Config{Count: 2, Segment: 2})
+	if _, err := MultiBuffer(closed, b).Write([]byte{1, 2}); errors.Cause(err) != ErrClosed {
+		t.Error(err, "should be", ErrClosed)
+	}
+	if _, err := b.ReadLast(io.Discard, 1); err != nil {
+		t.Error("write to other buffer should succeed:", err)
+	}
+}
+
+func TestMultiBuffer_EmptyWindow(t *testing.T) {
+	short := New(Config{Count: 2, Segment: 2})
+	short.SetCount(0)
+	w := MultiBuffer(short, NewDefault())
+	if n, err := w.Write([]byte{0, 1}); errors.Cause(err) != ErrTooLargeWrite || n != 0 {
+		t.Error(n, err, "should be", ErrTooLargeWrite)
+	}
+}
@@ -0,0 +1,73 @@
+package player
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PacketSize is usual size of UDP datagram with MPEG-TS, 7 packets of 188 bytes.
+const PacketSize = 7 * 188
+
+// SendPackets streams new segments to w as datagrams of at most size bytes
+// until ctx is done or write fails, e.g. to *net.UDPConn dialed to
+// multicast group. If segment duration is known, datagrams are spaced
+// evenly over it, so stream is sent at its bitrate without bursts.
+func (b *Buffer) SendPackets(ctx context.Context, w io.Writer, size int) error {
+	if size <= 0 {
+		size = PacketSize
+	}
+	b.l.Lock()
+	c := b.newCursor(b.nextID())
+	b.l.Unlock()
+	return b.streamTo(ctx, c, &packetWriter{
+		ctx:   ctx,
+		w:     w,
+		size:  size,
+		clock: b.clock,
+		d:     b.Duration(),
+	})
+}
+
+// packetWriter splits segments into datagrams, spacing them over
+// segment duration d.
+type packetWriter struct {
+	ctx   context.Context
+	w     io.Writer
+	size  int
+	clock Clock
+	d     time.Duration
+	next  time.Time // send time of next datagram
+}
+
+func (p *packetWriter) Write(seg []byte) (int, error) {
+	count := (len(seg) + p.size - 1) / p.size
+	var interval time.Duration
+	if p.d > 0 && count > 0 {
+		interval = p.d / time.Duration(count)
+	}
+	if now := p.clock.Now(); p.next.Before(now) {
+		// sender is late, spacing from now
+		p.next = now
+	}
+	n := 0
+	for n < len(seg) {
+		end := n + p.size
+		if end > len(seg) {
+			end = len(seg)
+		}
+		if interval > 0 {
+			if err := sleepUntil(p.ctx, p.clock, p.next); err != nil {
+				return n, errors.Wrap(err, "failed to send")
+			}
+			p.next = p.next.Add(interval)
+		}
+		if _, err := p.w.Write(seg[n:end]); err != nil {
+			return n, errors.Wrap(err, "failed to send")
+		}
+		n = end
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// packetRecorder records datagrams.
+type packetRecorder struct {
+	mux     sync.Mutex
+	packets [][]byte
+}
+
+func (r *packetRecorder) Write(p []byte) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.packets = append(r.packets, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (r *packetRecorder) count() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return len(r.packets)
+}
+
+func TestBuffer_SendPackets(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 10, Duration: time.Second, Clock: c})
+	r := new(packetRecorder)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- b.SendPackets(ctx, r, 4)
+	}()
+	for len(b.Cursors()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	seg := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if _, err := b.Write(seg); err != nil {
+		t.Fatal(err)
+	}
+	// first datagram is sent immediately, others are spaced by 1/3s
+	for i := 1; i < 3; i++ {
+		waitTimers(t, c, 1)
+		if n := r.count(); n != i {
+			t.Fatal("datagram should wait for spacing", n)
+		}
+		c.Advance(time.Second / 3)
+	}
+	deadline := time.Now().Add(time.Second)
+	for r.count() < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("datagrams should be sent")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !bytes.Equal(bytes.Join(r.packets, nil), seg) || len(r.packets[2]) != 2 {
+		t.Error("bad datagrams", r.packets)
+	}
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("should be cancelled")
+	}
+}
@@ -0,0 +1,41 @@
+package player
+
+import "time"
+
+// SegmentInfo is metadata of segment without payload.
+type SegmentInfo struct {
+	ID            int64
+	Completed     time.Time
+	Offset        int64 // logical byte offset, see ByteRange
+	Discontinuity bool
+	Meta          [][]byte // must not be modified
+}
+
+// PeekMeta returns metadata of segments in window with ids in [from, to)
+// without copying payload, e.g. for playlist generation. Range is
+// clamped to window.
+func (b *Buffer) PeekMeta(from, to int64) []SegmentInfo {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if from < b.firstID {
+		from = b.firstID
+	}
+	if next := b.nextID(); to > next {
+		to = next
+	}
+	if from >= to {
+		return nil
+	}
+	infos := make([]SegmentInfo, 0, to-from)
+	for id := from; id < to; id++ {
+		_, disc := b.disc[id]
+		infos = append(infos, SegmentInfo{
+			ID:            id,
+			Completed:     b.times[id-b.firstID],
+			Offset:        b.offset + (id-b.firstID)*b.segment,
+			Discontinuity: disc,
+			Meta:          b.meta[id],
+		})
+	}
+	return infos
+}
@@ -0,0 +1,32 @@
+package player
+
+import (
+	"testing"
+)
+
+func TestBuffer_PeekMeta(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4})
+	if infos := b.PeekMeta(0, 10); len(infos) != 0 {
+		t.Error("empty buffer should have no segments", infos)
+	}
+	if _, err := b.Write([]byte{0, 0, 0, 0, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	b.WriteMeta([]byte("title"))
+	if _, err := b.Write([]byte{1, 1, 2, 2, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	infos := b.PeekMeta(0, 10)
+	if len(infos) != 2 {
+		t.Fatal("bad count", len(infos))
+	}
+	if i := infos[0]; i.ID != 1 || i.Offset != 4 || len(i.Meta) != 1 || i.Completed.IsZero() {
+		t.Errorf("bad info %+v", i)
+	}
+	if i := infos[1]; i.ID != 2 || i.Offset != 8 || len(i.Meta) != 0 {
+		t.Errorf("bad info %+v", i)
+	}
+	if infos := b.PeekMeta(2, 3); len(infos) != 1 || infos[0].ID != 2 {
+		t.Errorf("bad infos %+v", infos)
+	}
+}
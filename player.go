@@ -2,8 +2,14 @@
 package player
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"math"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -29,8 +35,41 @@ const (
 	ErrTooLargeWrite Error = "write is too large"
 	// ErrEmpty means that Buffer is empty.
 	ErrEmpty Error = "buffer is empty"
+	// ErrDropped means that Cursor was dropped by Buffer.
+	ErrDropped Error = "cursor dropped"
+	// ErrIDOverflow means that write would complete segment with id
+	// that does not fit int64.
+	ErrIDOverflow Error = "segment id overflow"
 )
 
+// IDError describes failed access to segment by id. It matches underlying
+// Error via errors.Is and errors.Cause.
+type IDError struct {
+	Err   Error  // ErrMiss or ErrEmpty
+	ID    int64  // requested id
+	First int64  // first id of window at the moment of error
+	Last  int64  // last id of window at the moment of error
+	Key   string // stream key from Config
+}
+
+func (e *IDError) Error() string {
+	msg := fmt.Sprintf("%s: id %d, window [%d, %d]", e.Err, e.ID, e.First, e.Last)
+	if e.Key != "" {
+		msg = e.Key + ": " + msg
+	}
+	return msg
+}
+
+// Unwrap returns underlying Error.
+func (e *IDError) Unwrap() error {
+	return e.Err
+}
+
+// Cause returns underlying Error.
+func (e *IDError) Cause() error {
+	return e.Err
+}
+
 // Buffer represents in-memory buffer for stream.
 type Buffer struct {
 	segment       int64
@@ -38,19 +77,116 @@ type Buffer struct {
 	count         int64
 	lastID        int64
 	firstID       int64
+	offset        int64 // logical byte offset of firstID in stream
+	generation    int64 // bumped on every eviction or reset
+	key           string
 	allowOverflow bool
-	l             sync.Mutex
+	l             sync.RWMutex
 	data          []byte
+	meta          map[int64][][]byte // metadata track, keyed by segment id
+	times         []time.Time        // completion time of each segment
+	evicted       time.Time          // completion time of last evicted segment
+	cursors       map[*Cursor]struct{}
+	backpressure  Backpressure
+	duration      time.Duration
+	evictHook     func(id int64)
+	notify        chan struct{} // closed on state change
+	waiting       int           // writers and Shutdown waiting for cursors
+	pubFirst      int64         // firstID published for lock-free FirstID
+	pubLast       int64         // lastID published for lock-free LastID
+	pubNeed       int64         // bytes to complete segment, for coalescing
+	stats         Stats
+	tracer        Tracer
+	log           Logger
+	transforms    []Transform
+	pool          sync.Pool // scratch segment buffers
+	completed     time.Time // completion time of last segment
+	stalled       bool      // ingest is stalled, set by Watch
+	stallHook     func(last time.Time)
+	segmentHook   func(id int64, completed time.Time, data []byte)
+	resumeHook    func(stalled time.Duration)
+	disc          map[int64]struct{} // ids of discontinuity segments
+	slate         func(id int64) []byte
+	slated        bool // slate was injected during current stall
+	closed        bool // writes are rejected, set by Shutdown
+	clock         Clock
+	archive       Archive
+	readback      *readback // cache of segments fetched from archive
+	prefetchCount int64
+	coalesce      bool
+	sl            sync.Mutex // guards staged
+	staged        []byte     // coalesced small writes
+	tl            sync.Mutex // guards tees, orders tee writes
+	tees          []*tee
+	el            sync.Mutex // guards subscribers
+	subscribers   []*subscriber
+
+	markDiscontinuity bool
 }
 
 // Config is configuration for Buffer.
 type Config struct {
-	Segment int64
-	Count   int64
-	Start   int64
+	// Key identifies stream in errors.
+	Key     string `json:"key"`
+	Segment int64  `json:"segment"`
+	Count   int64  `json:"count"`
+	Start   int64  `json:"start"`
+	// Duration is declared playback duration of one segment,
+	// zero if unknown.
+	Duration time.Duration `json:"duration"`
+	// EpochStart derives Start from wall clock as EpochID of current
+	// time, so independently started publishers of the same stream
+	// produce consistent ids. Requires Duration. Ids stay aligned with
+	// wall clock while segments are written in real time, Slate can be
+	// used to keep them aligned during ingest stalls.
+	EpochStart bool `json:"epoch_start"`
+	// OnEvict is called with id of every evicted segment, oldest first.
+	OnEvict func(id int64) `json:"-"`
+	// OnSegment is called with every completed segment, its completion
+	// time and data as stored. Data must not be modified.
+	OnSegment func(id int64, completed time.Time, data []byte) `json:"-"`
+	// OnStall is called by Watch when ingest is stalled, with time
+	// of last completed segment.
+	OnStall func(last time.Time) `json:"-"`
+	// OnResume is called when ingest resumes after stall, with
+	// duration since last completed segment.
+	OnResume func(stalled time.Duration) `json:"-"`
+	// MarkDiscontinuity marks first segment written after stall
+	// as discontinuity.
+	MarkDiscontinuity bool `json:"mark_discontinuity"`
+	// Slate returns filler segment with provided id that is injected
+	// by Watch while ingest is stalled, see StaticSlate.
+	Slate func(id int64) []byte `json:"-"`
+	// Archive is used by ReadID and Get to read segments that were
+	// evicted from Buffer.
+	Archive Archive `json:"-"`
+	// ArchiveCache is count of segments fetched from Archive that are
+	// cached in memory, default is Count.
+	ArchiveCache int `json:"archive_cache"`
+	// Prefetch is count of evicted segments that are fetched from
+	// Archive ahead of Cursor that reads them sequentially.
+	Prefetch int64 `json:"prefetch"`
+	// Coalesce stages small writes outside of Buffer lock until they
+	// complete segment, reducing lock contention when data is written
+	// in small chunks, e.g. by single TS packets. Ignored if Transforms
+	// are set, because they are applied to every write.
+	Coalesce bool `json:"coalesce"`
+	// Clock is source of time, system clock by default.
+	Clock Clock `json:"-"`
+	// Tracer traces Buffer operations, no-op by default.
+	Tracer Tracer `json:"-"`
+	// Logger receives warnings, e.g. about dropped cursors.
+	Logger Logger `json:"-"`
+	// Transforms are applied to written data in order and to data
+	// delivered by ReadID and Cursor in reverse order. Get returns
+	// data as stored.
+	Transforms []Transform `json:"-"`
 	// AllowOverflow allows Buffer.Write to accept buffer which size
 	// is larger than maximum internal buffer size (count * segment).
-	AllowOverflow bool
+	AllowOverflow bool `json:"allow_overflow"`
+	// Backpressure sets Write behaviour when registered cursors
+	// are lagging, default is BackpressureNone.
+	Backpressure Backpressure `json:"backpressure"`
 }
 
 // New creates new Buffer with specified settings. If value in Config is zero,
@@ -62,11 +198,50 @@ func New(cfg Config) *Buffer {
 	if cfg.Count == 0 {
 		cfg.Count = 8
 	}
+	if cfg.Tracer == nil {
+		cfg.Tracer = noopTracer{}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = noopLogger{}
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	if cfg.EpochStart && cfg.Duration > 0 {
+		cfg.Start = EpochID(cfg.Clock.Now(), cfg.Duration)
+	}
+	if cfg.ArchiveCache == 0 {
+		cfg.ArchiveCache = int(cfg.Count)
+	}
 	return &Buffer{
-		segment:  cfg.Segment,
-		maxCount: cfg.Count,
-		firstID:  cfg.Start,
+		segment:       cfg.Segment,
+		maxCount:      cfg.Count,
+		firstID:       cfg.Start,
 		allowOverflow: cfg.AllowOverflow,
+		backpressure:  cfg.Backpressure,
+		duration:      cfg.Duration,
+		evictHook:     cfg.OnEvict,
+		key:           cfg.Key,
+		tracer:        cfg.Tracer,
+		log:           cfg.Logger,
+		transforms:    cfg.Transforms,
+		pubFirst:      cfg.Start,
+		stallHook:     cfg.OnStall,
+		segmentHook:   cfg.OnSegment,
+		resumeHook:    cfg.OnResume,
+		slate:         cfg.Slate,
+		clock:         cfg.Clock,
+		archive:       cfg.Archive,
+		prefetchCount: cfg.Prefetch,
+		coalesce:      cfg.Coalesce && len(cfg.Transforms) == 0,
+		pubNeed:       cfg.Segment,
+		readback: &readback{
+			max:      cfg.ArchiveCache,
+			entries:  make(map[int64][]byte),
+			inflight: make(map[int64]*archiveCall),
+		},
+
+		markDiscontinuity: cfg.MarkDiscontinuity,
 	}
 }
 
@@ -75,111 +250,687 @@ func NewDefault() *Buffer {
 	return New(Config{})
 }
 
-// SetCount sets maximum segment count.
+// SetCount sets maximum segment count. Growing preserves all segments,
+// shrinking immediately evicts oldest segments that do not fit and
+// releases their memory.
 func (b *Buffer) SetCount(count int64) {
 	b.l.Lock()
 	b.maxCount = count
+	from, to := b.evict()
+	if to > from {
+		// evict only reslices, so copying retained data to let
+		// evicted segments be collected
+		b.data = append(make([]byte, 0, len(b.data)), b.data...)
+		b.times = append([]time.Time(nil), b.times...)
+		b.broadcast()
+	}
 	b.l.Unlock()
+	b.onEvict(from, to)
 }
 
 // SegmentSize returns size of segment.
 func (b *Buffer) SegmentSize() int64 {
+	b.l.RLock()
+	defer b.l.RUnlock()
 	return b.segment
 }
 
+// Duration returns declared duration of segment.
+func (b *Buffer) Duration() time.Duration {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.duration
+}
+
 // Count returns current maximum segment count.
 func (b *Buffer) Count() int64 {
-	b.l.Lock()
-	defer b.l.Unlock()
+	b.l.RLock()
+	defer b.l.RUnlock()
 	return b.maxCount
 }
 
 // Size returns current data length.
 func (b *Buffer) Size() int {
-	b.l.Lock()
-	defer b.l.Unlock()
+	b.l.RLock()
+	defer b.l.RUnlock()
 	return len(b.data)
 }
 
 // getSegment returns buffer for segment with id. No checks and locks.
 func (b *Buffer) getSegment(id int64) []byte {
+	atomic.AddInt64(&b.stats.BytesOut, b.segment)
 	start := b.segment * (id - b.firstID)
 	return b.data[start : b.segment+start]
 }
 
 // Write appends internal buffer with new data.
 func (b *Buffer) Write(buf []byte) (int, error) {
+	return b.WriteContext(context.Background(), buf)
+}
+
+// WriteContext appends internal buffer with new data. If BackpressureBlock
+// is set, it blocks until lagging cursors catch up or ctx is done.
+func (b *Buffer) WriteContext(ctx context.Context, buf []byte) (int, error) {
+	if b.coalesce {
+		return b.writeStaged(ctx, buf)
+	}
+	return b.write(ctx, -1, buf)
+}
+
+// write appends internal buffer with new data. If id is not negative,
+// buf should be whole segment with that id.
+func (b *Buffer) write(ctx context.Context, id int64, buf []byte) (int, error) {
+	return b.writeAs(ctx, id, buf, false)
+}
+
+// writeAs is write that writes slate segment with provided id if slate
+// is set. Slate is written only while ingest is stalled, and does not
+// resume it.
+func (b *Buffer) writeAs(ctx context.Context, id int64, buf []byte, slate bool) (n int, err error) {
+	span := b.tracer.Start(ctx, SpanInfo{Op: "Write", Key: b.key, ID: id})
+	defer func() {
+		span.End(n, err)
+	}()
+
+	n = len(buf)
+	if buf, err = b.transformWrite(buf); err != nil {
+		return 0, errors.Wrap(err, "failed to transform")
+	}
+
+	var dropped []*Cursor
+	b.l.Lock()
+	if b.closed {
+		b.l.Unlock()
+		return 0, errors.Wrap(ErrClosed, "failed to write")
+	}
 	if int64(len(buf)) > b.segment*b.maxCount {
 		// buffer length is bigger than maximum size.
 		if !b.allowOverflow {
+			b.l.Unlock()
 			return 0, errors.Wrap(ErrTooLargeWrite, "failed to write")
 		}
+		b.log.Printf("player: %s: overflow write of %d bytes", b.key, len(buf))
+	}
+	for b.backpressure != BackpressureNone {
+		blocking := b.blocking(int64(len(buf)))
+		if len(blocking) == 0 {
+			break
+		}
+		if b.backpressure == BackpressureDrop {
+			for _, c := range blocking {
+				c.dropped = true
+				delete(b.cursors, c)
+			}
+			dropped = blocking
+			break
+		}
+		changed := b.changed()
+		b.waiting++
+		b.l.Unlock()
+		select {
+		case <-ctx.Done():
+			b.l.Lock()
+			b.waiting--
+			b.l.Unlock()
+			return 0, errors.Wrap(ctx.Err(), "failed to write")
+		case <-changed:
+		}
+		b.l.Lock()
+		b.waiting--
+		if b.closed {
+			b.l.Unlock()
+			return 0, errors.Wrap(ErrClosed, "failed to write")
+		}
+	}
+	if b.overflowsID(int64(len(buf))) {
+		b.l.Unlock()
+		return 0, errors.Wrap(ErrIDOverflow, "failed to write")
+	}
+	if id >= 0 {
+		if err := b.checkSegment(id, buf); err != nil {
+			b.l.Unlock()
+			if err == errDuplicate {
+				return n, nil
+			}
+			return 0, errors.Wrap(err, "failed to write")
+		}
 	}
+	if slate && !b.stalled {
+		// ingest resumed
+		b.l.Unlock()
+		return 0, nil
+	}
+	now := b.clock.Now()
+	var stalled time.Duration
+	if slate {
+		if !b.slated {
+			b.addDiscontinuity(id)
+			b.slated = true
+		}
+	} else if b.stalled {
+		// ingest resumed
+		b.stalled = false
+		stalled = now.Sub(b.completed)
+		if b.markDiscontinuity || b.slated {
+			b.addDiscontinuity(b.nextID())
+		}
+		b.slated = false
+	}
+	next := b.nextID()
+	from, to := b.store(buf, now)
+	completed := b.completedSince(next)
 
-	b.l.Lock()
-	b.data = append(b.data, buf...) // ALLOCATIONS: suboptimal.
+	b.tl.Lock() // taken before unlock to keep order of stores
+	b.broadcast()
+	b.l.Unlock()
+	b.tee(buf)
+	if len(dropped) > 0 {
+		b.log.Printf("player: %s: dropped %d slow cursors", b.key, len(dropped))
+		for _, c := range dropped {
+			b.emit(Event{Kind: EventReaderDropped, ID: -1, Cursor: c})
+		}
+	}
+	b.onEvict(from, to)
+	b.onSegment(completed)
+	if stalled > 0 {
+		b.log.Printf("player: %s: ingest resumed after %s", b.key, stalled)
+		if b.resumeHook != nil {
+			b.resumeHook(stalled)
+		}
+	}
+	return n, nil
+}
+
+// store appends buf to internal buffer, updating window, and returns
+// range [from, to) of evicted ids. No locks.
+func (b *Buffer) store(buf []byte, now time.Time) (from, to int64) {
+	from = b.firstID
+	if k := b.overflow(int64(len(b.data) + len(buf))); k > 0 && k*b.segment >= int64(len(b.data)) {
+		// fast path: write replaces whole window, so copying
+		// only retained tail of buf
+		tail := buf[k*b.segment-int64(len(b.data)):]
+		b.data = append(make([]byte, 0, len(tail)), tail...)
+		b.times = b.times[:0]
+		b.evicted = now
+		b.skip(k)
+	} else {
+		b.data = append(b.data, buf...) // ALLOCATIONS: suboptimal.
+	}
+	b.stats.Writes++
+	b.stats.BytesIn += int64(len(buf))
+	if int64(len(buf)) > b.stats.MaxWrite {
+		b.stats.MaxWrite = int64(len(buf))
+	}
 	b.count = int64(len(b.data)) / b.segment
+	for int64(len(b.times)) < b.count {
+		b.times = append(b.times, now)
+		b.completed = now
+	}
 
 	// updating buffer window (firstID and lastID)
 	b.lastID = b.count + b.firstID - 1
-	// while internal buffer size > maximum size
-	for int64(len(b.data)) > b.segment*b.maxCount {
-		// CPU: suboptimal.
-		b.firstID++
-		b.data = b.data[b.segment:]
+	_, to = b.evict()
+	return from, to
+}
+
+// overflowsID reports whether write of n bytes would advance nextID past
+// math.MaxInt64. No locks.
+func (b *Buffer) overflowsID(n int64) bool {
+	completed := (int64(len(b.data))%b.segment + n) / b.segment
+	return b.nextID() > math.MaxInt64-completed
+}
+
+// completedSegment is segment passed to OnSegment hook.
+type completedSegment struct {
+	id   int64
+	time time.Time
+	data []byte
+}
+
+// completedSince returns segments in window with id >= next, if OnSegment
+// hook is set. No locks.
+func (b *Buffer) completedSince(next int64) []completedSegment {
+	if b.segmentHook == nil && !b.subscribed() {
+		return nil
 	}
+	if next < b.firstID {
+		next = b.firstID
+	}
+	var segments []completedSegment
+	for id := next; id < b.nextID(); id++ {
+		start := b.segment * (id - b.firstID)
+		segments = append(segments, completedSegment{
+			id:   id,
+			time: b.times[id-b.firstID],
+			data: b.data[start : b.segment+start],
+		})
+	}
+	return segments
+}
 
-	b.l.Unlock()
-	return len(buf), nil
+// onSegment calls OnSegment hook and emits events for provided segments.
+// Should be called without lock.
+func (b *Buffer) onSegment(segments []completedSegment) {
+	for _, s := range segments {
+		if b.segmentHook != nil {
+			b.segmentHook(s.id, s.time, s.data)
+		}
+		b.emit(Event{Kind: EventSegmentWritten, ID: s.id, Time: s.time, Data: s.data})
+	}
+}
+
+// ReadFrom writes data from r to Buffer until EOF.
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	return b.ReadFromContext(context.Background(), r)
+}
+
+// ReadFromContext writes data from r to Buffer until EOF or ctx is done.
+// Context is checked between reads, so blocked r.Read is not interrupted.
+func (b *Buffer) ReadFromContext(ctx context.Context, r io.Reader) (int64, error) {
+	var total int64
+	buf := make([]byte, b.SegmentSize())
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, errors.Wrap(err, "failed to read")
+		}
+		n, err := r.Read(buf)
+		if n > 0 {
+			if _, werr := b.WriteContext(ctx, buf[:n]); werr != nil {
+				return total, werr
+			}
+			total += int64(n)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, errors.Wrap(err, "failed to read")
+		}
+	}
+}
+
+// evict drops oldest segments while internal buffer size > maximum size
+// and returns range [from, to) of evicted ids. No locks.
+func (b *Buffer) evict() (from, to int64) {
+	from = b.firstID
+	if k := b.overflow(int64(len(b.data))); k > 0 {
+		b.evicted = b.times[k-1]
+		b.times = b.times[k:]
+		b.data = b.data[k*b.segment:]
+		b.skip(k)
+	}
+	b.publish()
+	return from, b.firstID
+}
+
+// overflow returns count of full segments that should be evicted to fit
+// size bytes into window. No locks.
+func (b *Buffer) overflow(size int64) int64 {
+	over := size - b.segment*b.maxCount
+	if over <= 0 {
+		return 0
+	}
+	k := (over + b.segment - 1) / b.segment
+	if full := size / b.segment; k > full {
+		k = full
+	}
+	return k
+}
+
+// skip advances window by k segments, dropping their metadata.
+// Data and times are updated by caller. No locks.
+func (b *Buffer) skip(k int64) {
+	to := b.firstID + k
+	for id := range b.disc {
+		if id < to {
+			delete(b.disc, id)
+		}
+	}
+	if k < int64(len(b.meta)) {
+		for id := b.firstID; id < to; id++ {
+			delete(b.meta, id)
+		}
+	} else {
+		for id := range b.meta {
+			if id < to {
+				delete(b.meta, id)
+			}
+		}
+	}
+	b.firstID = to
+	b.offset += k * b.segment
+	b.generation++
+	b.stats.Evictions += k
+}
+
+// onEvict calls eviction hook and emits events for ids in [from, to).
+// Should be called without lock, so hook is able to use Buffer.
+func (b *Buffer) onEvict(from, to int64) {
+	for id := from; id < to; id++ {
+		if b.evictHook != nil {
+			b.evictHook(id)
+		}
+		b.emit(Event{Kind: EventSegmentEvicted, ID: id})
+	}
 }
 
 func (b *Buffer) acquireID(id int64) error {
 	if len(b.data) == 0 {
-		return ErrEmpty
+		atomic.AddInt64(&b.stats.Misses, 1)
+		return b.idError(ErrEmpty, id)
 	}
 	if id < b.firstID || id > b.lastID {
-		return ErrMiss
+		atomic.AddInt64(&b.stats.Misses, 1)
+		return b.idError(ErrMiss, id)
 	}
+	atomic.AddInt64(&b.stats.Hits, 1)
 	return nil
 }
 
+// idError returns IDError with current window state. No locks.
+func (b *Buffer) idError(err Error, id int64) *IDError {
+	return &IDError{
+		Err:   err,
+		ID:    id,
+		First: b.firstID,
+		Last:  b.nextID() - 1,
+		Key:   b.key,
+	}
+}
+
 // ReadID reads semgent with provided id to w.
-func (b *Buffer) ReadID(w io.Writer, id int64) (int, error) {
-	b.l.Lock() // should be unlocked before w.Write call
+func (b *Buffer) ReadID(w io.Writer, id int64) (n int, err error) {
+	span := b.tracer.Start(context.Background(), SpanInfo{Op: "ReadID", Key: b.key, ID: id})
+	defer func() {
+		span.End(n, err)
+	}()
+
+	b.l.RLock() // should be unlocked before w.Write call
 	if err := b.acquireID(id); err != nil {
-		b.l.Unlock()
-		return 0, errors.Wrap(err, "bad id")
+		evicted := b.archive != nil && id < b.firstID
+		b.l.RUnlock()
+		if !evicted {
+			return 0, errors.Wrap(err, "bad id")
+		}
+		data, err := b.fromArchive(id, err)
+		if err != nil {
+			return 0, errors.Wrap(err, "bad id")
+		}
+		p := b.getBuffer(int64(len(data)))
+		defer b.putBuffer(p)
+		copy(*p, data)
+		buf, err := b.transformRead(id, *p)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to transform")
+		}
+		return w.Write(buf)
+	}
+	p := b.getBuffer(b.segment)
+	defer b.putBuffer(p)
+	copy(*p, b.getSegment(id))
+	b.l.RUnlock()
+	buf, err := b.transformRead(id, *p)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to transform")
 	}
-	var buf []byte
-	copy(buf, b.getSegment(id))
-	b.l.Unlock()
 	return w.Write(buf)
 }
 
 // Get writes segment with requested id into buf
-func (b *Buffer) Get(buf []byte, id int64) error {
+func (b *Buffer) Get(buf []byte, id int64) (err error) {
+	var n int
+	span := b.tracer.Start(context.Background(), SpanInfo{Op: "Get", Key: b.key, ID: id})
+	defer func() {
+		span.End(n, err)
+	}()
+
+	b.l.RLock()
 	if int64(len(buf)) < b.segment {
+		b.l.RUnlock()
 		return errors.Wrap(ErrBufferTooSmall, "bad buffer")
 	}
-	b.l.Lock()
 	if err := b.acquireID(id); err != nil {
-		return errors.Wrap(err, "bad id")
+		evicted := b.archive != nil && id < b.firstID
+		b.l.RUnlock()
+		if !evicted {
+			return errors.Wrap(err, "bad id")
+		}
+		data, err := b.fromArchive(id, err)
+		if err != nil {
+			return errors.Wrap(err, "bad id")
+		}
+		n = copy(buf, data)
+		return nil
 	}
-	copy(buf[:b.segment], b.getSegment(id))
-	b.l.Unlock()
+	n = copy(buf[:b.segment], b.getSegment(id))
+	b.l.RUnlock()
 	return nil
 }
 
-// LastID returns last segment id.
+// GetLatest writes last complete segment into buf and returns its id.
+func (b *Buffer) GetLatest(buf []byte) (int64, error) {
+	b.l.RLock()
+	id, n, err := b.getFrom(buf, b.nextID()-1)
+	b.l.RUnlock()
+	b.traceGet("GetLatest", id, n, err)
+	return id, err
+}
+
+// GetFrom writes segment with provided id into buf, or first segment of
+// window if it is already evicted, and returns id of written segment.
+func (b *Buffer) GetFrom(buf []byte, id int64) (int64, error) {
+	b.l.RLock()
+	if id < b.firstID {
+		id = b.firstID
+	}
+	id, n, err := b.getFrom(buf, id)
+	b.l.RUnlock()
+	b.traceGet("GetFrom", id, n, err)
+	return id, err
+}
+
+// getFrom copies segment with provided id into buf and returns its id
+// and size. No locks.
+func (b *Buffer) getFrom(buf []byte, id int64) (int64, int, error) {
+	if int64(len(buf)) < b.segment {
+		return id, 0, errors.Wrap(ErrBufferTooSmall, "bad buffer")
+	}
+	if err := b.acquireID(id); err != nil {
+		return id, 0, errors.Wrap(err, "bad id")
+	}
+	return id, copy(buf[:b.segment], b.getSegment(id)), nil
+}
+
+// traceGet records span of copy of segment that is resolved under lock,
+// so span starts after id is known. Tracer is called without lock.
+func (b *Buffer) traceGet(op string, id int64, n int, err error) {
+	b.tracer.Start(context.Background(), SpanInfo{Op: op, Key: b.key, ID: id}).End(n, err)
+}
+
+// latest returns id range of up to n newest complete segments. No locks.
+func (b *Buffer) latest(n int) (from, to int64) {
+	to = b.nextID()
+	from = to - int64(n)
+	if from < b.firstID {
+		from = b.firstID
+	}
+	return from, to
+}
+
+// LatestIDs returns ids of up to n newest complete segments in ascending
+// order, e.g. for initial playlist.
+func (b *Buffer) LatestIDs(n int) []int64 {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	from, to := b.latest(n)
+	ids := make([]int64, 0, to-from)
+	for id := from; id < to; id++ {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ReadLast reads up to n newest complete segments to w, oldest first, so
+// player can join near live edge. Segments are copied under one lock.
+// Returns ErrEmpty if there are no complete segments.
+func (b *Buffer) ReadLast(w io.Writer, n int) (int, error) {
+	b.l.RLock() // should be unlocked before w.Write call
+	from, to := b.latest(n)
+	if from >= to {
+		b.l.RUnlock()
+		return 0, errors.Wrap(ErrEmpty, "no segments")
+	}
+	p := b.getBuffer(b.segment * (to - from))
+	defer b.putBuffer(p)
+	for id := from; id < to; id++ {
+		copy((*p)[b.segment*(id-from):], b.getSegment(id))
+	}
+	segment := b.segment
+	b.l.RUnlock()
+
+	var total int
+	for id := from; id < to; id++ {
+		n, err := b.readLast(w, id, (*p)[segment*(id-from):segment*(id-from+1)])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// readLast writes copy of segment with provided id to w for ReadLast.
+func (b *Buffer) readLast(w io.Writer, id int64, p []byte) (n int, err error) {
+	span := b.tracer.Start(context.Background(), SpanInfo{Op: "ReadLast", Key: b.key, ID: id})
+	defer func() {
+		span.End(n, err)
+	}()
+	buf, err := b.transformRead(id, p)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to transform")
+	}
+	return w.Write(buf)
+}
+
+// GetGen is Get that also returns generation of window at the moment of
+// copy, so caller can detect that window was changed since.
+func (b *Buffer) GetGen(buf []byte, id int64) (int64, error) {
+	gen, n, err := b.getGen(buf, id)
+	b.traceGet("GetGen", id, n, err)
+	return gen, err
+}
+
+// getGen copies segment with id to buf and returns generation and count
+// of copied bytes.
+func (b *Buffer) getGen(buf []byte, id int64) (int64, int, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if int64(len(buf)) < b.segment {
+		return b.generation, 0, errors.Wrap(ErrBufferTooSmall, "bad buffer")
+	}
+	if err := b.acquireID(id); err != nil {
+		return b.generation, 0, errors.Wrap(err, "bad id")
+	}
+	n := copy(buf[:b.segment], b.getSegment(id))
+	return b.generation, n, nil
+}
+
+// Generation returns monotonically increasing number that is bumped
+// on every eviction or reset of window, so caller holding ids or
+// resuming cursor can detect that window it knew about is gone.
+func (b *Buffer) Generation() int64 {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.generation
+}
+
+// SegmentReader returns io.ReadSeeker over copy of segment with provided
+// id, e.g. for serving byte-range requests via http.ServeContent.
+func (b *Buffer) SegmentReader(id int64) (io.ReadSeeker, error) {
+	buf := make([]byte, b.SegmentSize())
+	if err := b.Get(buf, id); err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(buf), nil
+}
+
+// ByteRange returns logical byte offset of segment with provided id from
+// the start of stream and its length. Offsets are stable across evictions,
+// so whole stream can be addressed as one growing file, e.g. for
+// EXT-X-BYTERANGE playlists.
+func (b *Buffer) ByteRange(id int64) (offset, length int64, err error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if err := b.acquireID(id); err != nil {
+		return 0, 0, errors.Wrap(err, "bad id")
+	}
+	return b.offset + (id-b.firstID)*b.segment, b.segment, nil
+}
+
+// publish makes current window bounds visible to FirstID and LastID.
+// Should be called under write lock after window change.
+func (b *Buffer) publish() {
+	atomic.StoreInt64(&b.pubFirst, b.firstID)
+	atomic.StoreInt64(&b.pubLast, b.lastID)
+	atomic.StoreInt64(&b.pubNeed, b.segment-int64(len(b.data))%b.segment)
+}
+
+// LastID returns last segment id. It does not take the lock.
 func (b *Buffer) LastID() int64 {
-	b.l.Lock()
-	defer b.l.Unlock()
-	return b.lastID
+	return atomic.LoadInt64(&b.pubLast)
+}
+
+// Window returns range of ids in buffer and which of them are readable.
+// present[i] reports whether segment first+i can be read. Window is empty
+// when last < first.
+func (b *Buffer) Window() (first, last int64, present []bool) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	first, last = b.firstID, b.nextID()-1
+	present = make([]bool, last-first+1)
+	for i := range present {
+		// segments are written sequentially, so there are no gaps
+		present[i] = true
+	}
+	return first, last, present
+}
+
+// WindowInfo is consistent snapshot of Buffer window.
+type WindowInfo struct {
+	First, Last         int64     // ids of first and last complete segments, Last < First if empty
+	FirstTime, LastTime time.Time // completion times of first and last segments
+	Bytes               int64     // stored bytes, including partial segment
+	AvgDuration         time.Duration
+	Generation          int64
 }
 
-// FirstID returns first segment id.
+// WindowInfo returns window bounds, timestamps, size and average segment
+// duration taken under one lock. Segments are written sequentially, so
+// window has no gaps.
+func (b *Buffer) WindowInfo() WindowInfo {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	w := WindowInfo{
+		First:      b.firstID,
+		Last:       b.nextID() - 1,
+		Bytes:      int64(len(b.data)),
+		Generation: b.generation,
+	}
+	if n := len(b.times); n > 0 {
+		w.FirstTime, w.LastTime = b.times[0], b.times[n-1]
+		// first segment starts at completion of previous one, if known
+		start, count := b.evicted, n
+		if start.IsZero() {
+			start, count = b.times[0], n-1
+		}
+		if count > 0 {
+			w.AvgDuration = w.LastTime.Sub(start) / time.Duration(count)
+		}
+	}
+	return w
+}
+
+// FirstID returns first segment id. It does not take the lock.
 func (b *Buffer) FirstID() int64 {
-	b.l.Lock()
-	defer b.l.Unlock()
-	return b.firstID
+	return atomic.LoadInt64(&b.pubFirst)
 }
@@ -2,9 +2,12 @@ package player
 
 import (
 	"bytes"
+	"context"
 	"io"
+	"math"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -29,6 +32,23 @@ func TestBuffer_Write(t *testing.T) {
 	}
 }
 
+func TestBuffer_ReadFromContext(t *testing.T) {
+	b := NewDefault()
+	r := io.LimitReader(Rand(), b.SegmentSize()*3)
+	n, err := b.ReadFromContext(context.Background(), r)
+	if err != nil || n != b.SegmentSize()*3 {
+		t.Error(err, n)
+	}
+	if b.LastID() != 2 {
+		t.Error("bad last id", b.LastID())
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.ReadFromContext(ctx, Rand()); errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+}
+
 func TestBuffer_SetCount(t *testing.T) {
 	b := NewDefault()
 	c := b.Count() + 5
@@ -42,6 +62,38 @@ func TestBuffer_SetCount(t *testing.T) {
 	}
 }
 
+func TestBuffer_SetCountResize(t *testing.T) {
+	var evicted []int64
+	b := New(Config{
+		Count:   4,
+		Segment: 512,
+		OnEvict: func(id int64) {
+			evicted = append(evicted, id)
+		},
+	})
+	buf := make([]byte, 4*512)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	b.SetCount(8)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if b.FirstID() != 0 || b.LastID() != 7 || len(evicted) != 0 {
+		t.Error("segments should be preserved", b.FirstID(), b.LastID())
+	}
+	b.SetCount(5)
+	if b.FirstID() != 3 || b.Size() != 5*512 {
+		t.Error("oldest segments should be evicted", b.FirstID(), b.Size())
+	}
+	if len(evicted) != 3 || evicted[0] != 0 || evicted[2] != 2 {
+		t.Error("bad evicted", evicted)
+	}
+	if err := b.Get(buf, 7); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestBuffer_Size(t *testing.T) {
 	b := New(Config{
 		Count:   12,
@@ -71,6 +123,50 @@ func TestBuffer_Collection(t *testing.T) {
 	}
 }
 
+func TestBuffer_OverflowWrite(t *testing.T) {
+	var evicted int
+	b := New(Config{
+		Count:         4,
+		Segment:       512,
+		AllowOverflow: true,
+		OnEvict: func(id int64) {
+			evicted++
+		},
+	})
+	b.WriteMeta([]byte("evicted"))
+	if _, err := b.Write(make([]byte, 512+100)); err != nil {
+		t.Error(err)
+	}
+	data := new(bytes.Buffer)
+	if _, err := io.CopyN(data, Rand(), 512*10+50); err != nil {
+		t.Error(err)
+	}
+	if _, err := b.Write(data.Bytes()); err != nil {
+		t.Error(err)
+	}
+	// 11 full segments and 200 bytes are written, last 4 segments
+	// should be retained, including partial one
+	if b.FirstID() != 8 || b.LastID() != 10 || b.Size() != 512*3+150 {
+		t.Error("bad window", b.FirstID(), b.LastID(), b.Size())
+	}
+	if evicted != 8 {
+		t.Error("bad evicted count", evicted)
+	}
+	buf := make([]byte, 512)
+	if err := b.Get(buf, 8); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(buf, data.Bytes()[512*7-100:512*8-100]) {
+		t.Error("data mismatch")
+	}
+	if len(b.meta) != 0 {
+		t.Error("metadata of evicted segments should be dropped")
+	}
+	if offset, _, err := b.ByteRange(8); err != nil || offset != 512*8 {
+		t.Error("bad offset", offset, err)
+	}
+}
+
 func TestBuffer_ReadID(t *testing.T) {
 	r := Rand()
 	b := NewDefault()
@@ -104,6 +200,63 @@ func TestBuffer_Get(t *testing.T) {
 	}
 }
 
+func TestBuffer_SegmentReader(t *testing.T) {
+	r := Rand()
+	b := NewDefault()
+	if _, err := io.CopyN(b, r, b.SegmentSize()*4); err != nil {
+		t.Error(err)
+	}
+	seg := make([]byte, b.SegmentSize())
+	if err := b.Get(seg, 2); err != nil {
+		t.Error(err)
+	}
+	rs, err := b.SegmentReader(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs.Seek(100, io.SeekStart); err != nil {
+		t.Error(err)
+	}
+	buf := make([]byte, 50)
+	if _, err := io.ReadFull(rs, buf); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(buf, seg[100:150]) {
+		t.Error("data mismatch")
+	}
+	if _, err := b.SegmentReader(b.Count() * 2); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	// buffer should stay unlocked after miss
+	if _, err := b.SegmentReader(2); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBuffer_ByteRange(t *testing.T) {
+	b := New(Config{
+		Count:   2,
+		Segment: 512,
+		Start:   10,
+	})
+	buf := make([]byte, 512*2)
+	for i := 0; i < 2; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	offset, length, err := b.ByteRange(13)
+	if err != nil {
+		t.Error(err)
+	}
+	if offset != 3*512 || length != 512 {
+		t.Error("bad range", offset, length)
+	}
+	if _, _, err := b.ByteRange(11); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
 func TestBuffer_ID(t *testing.T) {
 	b := NewDefault()
 	buf := make([]byte, b.SegmentSize()*3)
@@ -122,6 +275,58 @@ func TestBuffer_ID(t *testing.T) {
 	}
 }
 
+func TestBuffer_Window(t *testing.T) {
+	b := New(Config{
+		Count:   3,
+		Segment: 512,
+		Start:   5,
+	})
+	if first, last, present := b.Window(); last >= first || len(present) != 0 {
+		t.Error("window should be empty", first, last, present)
+	}
+	buf := make([]byte, 512*2+100)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	first, last, present := b.Window()
+	if first != 5 || last != 6 || len(present) != 2 {
+		t.Error("bad window", first, last, present)
+	}
+	for i, ok := range present {
+		if !ok {
+			t.Error("segment should be present", first+int64(i))
+		}
+	}
+}
+
+func TestBuffer_Generation(t *testing.T) {
+	b := New(Config{
+		Count:   2,
+		Segment: 512,
+	})
+	buf := make([]byte, 512*2)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	gen, err := b.GetGen(buf, 1)
+	if err != nil || gen != 0 {
+		t.Error(err, gen)
+	}
+	if _, err := b.Write(buf[:512]); err != nil {
+		t.Error(err)
+	}
+	if b.Generation() <= gen {
+		t.Error("generation should be bumped on eviction")
+	}
+	gen = b.Generation()
+	if err := b.Resegment(256); err != nil {
+		t.Error(err)
+	}
+	if b.Generation() <= gen {
+		t.Error("generation should be bumped on resegment")
+	}
+}
+
 func TestBuffer_ErrMiss(t *testing.T) {
 	b := NewDefault()
 	buf := new(bytes.Buffer)
@@ -130,6 +335,64 @@ func TestBuffer_ErrMiss(t *testing.T) {
 	}
 }
 
+func TestIDError(t *testing.T) {
+	b := New(Config{
+		Key:     "stream",
+		Count:   2,
+		Segment: 512,
+		Start:   3,
+	})
+	buf := make([]byte, 512)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	err := b.Get(buf, 10)
+	if !errors.Is(err, ErrMiss) {
+		t.Error(err, "should be", ErrMiss)
+	}
+	var idErr *IDError
+	if !errors.As(err, &idErr) {
+		t.Fatal(err, "should be IDError")
+	}
+	if idErr.ID != 10 || idErr.First != 3 || idErr.Last != 3 || idErr.Key != "stream" {
+		t.Errorf("bad error %+v", idErr)
+	}
+	if idErr.Error() != "stream: buffer miss: id 10, window [3, 3]" {
+		t.Error("bad message", idErr.Error())
+	}
+}
+
+func TestBuffer_IDOverflow(t *testing.T) {
+	b := New(Config{
+		Count:   4,
+		Segment: 4,
+		Start:   math.MaxInt64 - 1,
+	})
+	if _, err := b.Write(make([]byte, 6)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write(make([]byte, 2)); errors.Cause(err) != ErrIDOverflow {
+		t.Error(err, "should be", ErrIDOverflow)
+	}
+	// partial segment can still be written
+	if _, err := b.Write(make([]byte, 1)); err != nil {
+		t.Error(err)
+	}
+	if first, last, _ := b.Window(); first != math.MaxInt64-1 || last != math.MaxInt64-1 {
+		t.Error("bad window", first, last)
+	}
+}
+
+func TestBuffer_NegativeStart(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4, Start: -1})
+	if _, err := b.Write(make([]byte, 4)); err != nil {
+		t.Fatal(err)
+	}
+	if first, last, _ := b.Window(); first != -1 || last != -1 {
+		t.Error("bad window", first, last)
+	}
+}
+
 func TestError(t *testing.T) {
 	if Error("error").Error() != "error" {
 		t.Error("bad Error")
@@ -137,4 +400,130 @@ func TestError(t *testing.T) {
 	if Error("error").String() != "err: error" {
 		t.Error("bad String for Error")
 	}
-}
\ No newline at end of file
+}
+func BenchmarkBuffer_ParallelRead(b *testing.B) {
+	buf := NewDefault()
+	if _, err := io.CopyN(buf, Rand(), buf.SegmentSize()*buf.Count()); err != nil {
+		b.Fatal(err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		// concurrent writer
+		seg := make([]byte, buf.SegmentSize())
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				buf.Write(seg)
+			}
+		}
+	}()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		scratch := buf.GetBuffer()
+		defer buf.PutBuffer(scratch)
+		for pb.Next() {
+			buf.Get(*scratch, buf.LastID())
+		}
+	})
+}
+
+func BenchmarkBuffer_OverflowWrite(b *testing.B) {
+	buf := New(Config{
+		Count:         8,
+		Segment:       1024,
+		AllowOverflow: true,
+	})
+	data := make([]byte, 1024*1024)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := buf.Write(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBuffer_WindowInfo(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	b := New(Config{Count: 2, Segment: 4, Clock: c})
+	if w := b.WindowInfo(); w.Last >= w.First || w.Bytes != 0 {
+		t.Errorf("bad empty window %+v", w)
+	}
+	if err := WriteSynthetic(b, c, 2, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if w := b.WindowInfo(); w.First != 0 || w.Last != 1 || w.AvgDuration != time.Second || w.Bytes != 8 {
+		t.Errorf("bad window %+v", w)
+	}
+	if err := WriteSynthetic(b, c, 1, time.Second*3); err != nil {
+		t.Fatal(err)
+	}
+	w := b.WindowInfo()
+	if w.First != 1 || w.Last != 2 || w.AvgDuration != time.Second*2 || w.Generation != 1 {
+		t.Errorf("bad window %+v", w)
+	}
+	if !w.FirstTime.Equal(start.Add(time.Second*2)) || !w.LastTime.Equal(start.Add(time.Second*5)) {
+		t.Errorf("bad window times %+v", w)
+	}
+}
+
+func TestBuffer_GetLatest(t *testing.T) {
+	b := New(Config{Count: 3, Segment: 4})
+	buf := make([]byte, 4)
+	if _, err := b.GetLatest(buf); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	for _, p := range [][]byte{{0, 0, 0, 0, 1, 1, 1, 1}, {2, 2, 2, 2}, {3, 3}} {
+		if _, err := b.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if id, err := b.GetLatest(buf); err != nil || id != 2 || buf[0] != 2 {
+		t.Error("bad latest", id, buf, err)
+	}
+	if id, err := b.GetFrom(buf, 0); err != nil || id != 1 || buf[0] != 1 {
+		t.Error("evicted id should resolve to first", id, buf, err)
+	}
+	if id, err := b.GetFrom(buf, 2); err != nil || id != 2 || buf[0] != 2 {
+		t.Error("bad segment", id, buf, err)
+	}
+	if _, err := b.GetFrom(buf, 3); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if _, err := b.GetLatest(buf[:1]); errors.Cause(err) != ErrBufferTooSmall {
+		t.Error(err, "should be", ErrBufferTooSmall)
+	}
+}
+
+func TestBuffer_ReadLast(t *testing.T) {
+	b := New(Config{Count: 3, Segment: 2})
+	if _, err := b.ReadLast(io.Discard, 2); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	if ids := b.LatestIDs(2); len(ids) != 0 {
+		t.Error("unexpected ids", ids)
+	}
+	if _, err := b.Write([]byte{0, 0, 1, 1, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte{3, 3, 4}); err != nil {
+		t.Fatal(err)
+	}
+	w := new(bytes.Buffer)
+	if n, err := b.ReadLast(w, 2); err != nil || n != 4 {
+		t.Fatal(n, err)
+	}
+	if !bytes.Equal(w.Bytes(), []byte{2, 2, 3, 3}) {
+		t.Error("bad segments", w.Bytes())
+	}
+	ids := b.LatestIDs(10)
+	if len(ids) != 2 || ids[0] != 2 || ids[1] != 3 {
+		t.Error("bad ids", ids)
+	}
+}
@@ -0,0 +1,96 @@
+package player
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Program is pre-recorded segment set scheduled for playout.
+type Program struct {
+	Start    time.Time
+	Segments [][]byte
+}
+
+// Playout feeds Buffer from scheduled programs in real time, one segment
+// per segment duration, until ctx is done. Between programs filler
+// segments are written in loop. First segment of every program and of
+// filler is marked as discontinuity, and trailing partial segment of
+// previous source is dropped. Programs should be sorted by start time
+// and should not overlap. Requires segment Duration, and every segment
+// should have the size of Buffer segment, otherwise ErrBadSegment is
+// returned before playout is started.
+func (b *Buffer) Playout(ctx context.Context, programs []Program, filler [][]byte) error {
+	d := b.Duration()
+	if d <= 0 {
+		return errors.Wrap(ErrBadConfig, "playout requires duration")
+	}
+	if len(filler) == 0 {
+		return errors.Wrap(ErrBadConfig, "playout requires filler")
+	}
+	size := b.SegmentSize()
+	for _, seg := range filler {
+		if int64(len(seg)) != size {
+			return errors.Wrapf(ErrBadSegment, "bad filler size %d", len(seg))
+		}
+	}
+	for _, p := range programs {
+		for _, seg := range p.Segments {
+			if int64(len(seg)) != size {
+				return errors.Wrapf(ErrBadSegment, "bad program segment size %d", len(seg))
+			}
+		}
+	}
+	var (
+		next    = b.clock.Now()
+		current = -2 // index of current program, -1 for filler
+		fill    int  // index of next filler segment
+	)
+	for {
+		if err := sleepUntil(ctx, b.clock, next); err != nil {
+			return errors.Wrap(err, "playout stopped")
+		}
+		p, i := scheduled(programs, next, d)
+		var seg []byte
+		if p < 0 {
+			if current != -1 {
+				fill = 0
+			}
+			seg = filler[fill%len(filler)]
+			fill++
+		} else {
+			seg = programs[p].Segments[i]
+		}
+		if p != current {
+			b.sl.Lock()
+			b.l.Lock()
+			if partial := b.dropPartial(); partial > 0 {
+				b.log.Printf("player: %s: dropped %d bytes of partial segment for playout", b.key, partial)
+			}
+			b.addDiscontinuity(b.nextID())
+			b.l.Unlock()
+			b.sl.Unlock()
+			current = p
+		}
+		if _, err := b.WriteContext(ctx, seg); err != nil {
+			return errors.Wrap(err, "failed to write")
+		}
+		next = next.Add(d)
+	}
+}
+
+// scheduled returns index of program and its segment that is scheduled
+// at t, or -1 if no program is scheduled.
+func scheduled(programs []Program, t time.Time, d time.Duration) (int, int) {
+	for p, program := range programs {
+		if t.Before(program.Start) {
+			continue
+		}
+		i := int(t.Sub(program.Start) / d)
+		if i < len(program.Segments) {
+			return p, i
+		}
+	}
+	return -1, 0
+}
@@ -0,0 +1,91 @@
+package player
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Playout(t *testing.T) {
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+	b := New(Config{Count: 16, Segment: 1, Duration: time.Second, Clock: c})
+	programs := []Program{
+		{Start: start.Add(time.Second * 2), Segments: [][]byte{{1}, {2}}},
+	}
+	filler := [][]byte{{8}, {9}}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Playout(ctx, programs, filler)
+	}()
+	for i := 0; i < 6; i++ {
+		waitTimers(t, c, 1)
+		c.Advance(time.Second)
+	}
+	waitTimers(t, c, 1)
+	cancel()
+	if err := <-done; errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+
+	buf := make([]byte, 1)
+	var got []byte
+	var disc []int64
+	for id := int64(0); id <= 6; id++ {
+		if err := b.Get(buf, id); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, buf[0])
+		if ok, _ := b.Discontinuity(id); ok {
+			disc = append(disc, id)
+		}
+	}
+	if string(got) != string([]byte{8, 9, 1, 2, 8, 9, 8}) {
+		t.Error("bad playout", got)
+	}
+	if len(disc) != 3 || disc[0] != 0 || disc[1] != 2 || disc[2] != 4 {
+		t.Error("bad discontinuities", disc)
+	}
+	if err := New(Config{}).Playout(ctx, nil, filler); errors.Cause(err) != ErrBadConfig {
+		t.Error(err, "should be", ErrBadConfig)
+	}
+}
+
+func TestBuffer_PlayoutSegments(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 2, Duration: time.Second, Clock: c})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := b.Playout(ctx, nil, [][]byte{{8, 8}, {9}}); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+	programs := []Program{{Segments: [][]byte{{1, 1, 1}}}}
+	if err := b.Playout(ctx, programs, [][]byte{{8, 8}}); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+
+	// partial segment of previous source is dropped
+	if _, err := b.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Playout(ctx, nil, [][]byte{{8, 8}})
+	}()
+	waitTimers(t, c, 1)
+	cancel()
+	if err := <-done; errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+	buf := make([]byte, 2)
+	if err := b.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf[0] != 8 || buf[1] != 8 || b.Partial() != 0 {
+		t.Error("bad playout", buf, b.Partial())
+	}
+}
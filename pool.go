@@ -0,0 +1,28 @@
+package player
+
+// getBuffer returns pooled scratch buffer with length of size.
+func (b *Buffer) getBuffer(size int64) *[]byte {
+	if p, ok := b.pool.Get().(*[]byte); ok && int64(cap(*p)) >= size {
+		*p = (*p)[:size]
+		return p
+	}
+	buf := make([]byte, size)
+	return &buf
+}
+
+// putBuffer returns buffer obtained by getBuffer to pool.
+func (b *Buffer) putBuffer(p *[]byte) {
+	b.pool.Put(p)
+}
+
+// GetBuffer returns pointer to segment-sized scratch buffer, e.g. for
+// Get. Buffer should be returned with PutBuffer when it is not used
+// anymore. Pointer is passed around, so pooling does not allocate.
+func (b *Buffer) GetBuffer() *[]byte {
+	return b.getBuffer(b.SegmentSize())
+}
+
+// PutBuffer returns buffer obtained by GetBuffer for reuse.
+func (b *Buffer) PutBuffer(p *[]byte) {
+	b.putBuffer(p)
+}
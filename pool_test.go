@@ -0,0 +1,111 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBuffer_GetBuffer(t *testing.T) {
+	b := NewDefault()
+	if _, err := io.CopyN(b, Rand(), b.SegmentSize()*2); err != nil {
+		t.Error(err)
+	}
+	buf := b.GetBuffer()
+	if int64(len(*buf)) != b.SegmentSize() {
+		t.Error("bad buffer size", len(*buf))
+	}
+	if err := b.Get(*buf, 1); err != nil {
+		t.Error(err)
+	}
+	b.PutBuffer(buf)
+	if err := b.Resegment(b.SegmentSize() * 2); err != nil {
+		t.Error(err)
+	}
+	if buf = b.GetBuffer(); int64(len(*buf)) != b.SegmentSize() {
+		t.Error("bad buffer size after resegment", len(*buf))
+	}
+}
+
+func TestBuffer_GetBufferAllocs(t *testing.T) {
+	b := NewDefault()
+	if _, err := io.CopyN(b, Rand(), b.SegmentSize()); err != nil {
+		t.Error(err)
+	}
+	b.PutBuffer(b.GetBuffer())
+	allocs := testing.AllocsPerRun(100, func() {
+		buf := b.GetBuffer()
+		if err := b.Get(*buf, 0); err != nil {
+			t.Error(err)
+		}
+		b.PutBuffer(buf)
+	})
+	if allocs != 0 {
+		t.Error("should not allocate", allocs)
+	}
+}
+
+func TestBuffer_ReadIDData(t *testing.T) {
+	b := NewDefault()
+	data := new(bytes.Buffer)
+	if _, err := io.CopyN(io.MultiWriter(b, data), Rand(), b.SegmentSize()*2); err != nil {
+		t.Error(err)
+	}
+	out := new(bytes.Buffer)
+	if _, err := b.ReadID(out, 1); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(out.Bytes(), data.Bytes()[b.SegmentSize():]) {
+		t.Error("data mismatch")
+	}
+}
+
+func BenchmarkBuffer_ReadID(b *testing.B) {
+	buf := NewDefault()
+	if _, err := io.CopyN(buf, Rand(), buf.SegmentSize()*4); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(buf.SegmentSize())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := buf.ReadID(io.Discard, 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuffer_Get(b *testing.B) {
+	buf := NewDefault()
+	if _, err := io.CopyN(buf, Rand(), buf.SegmentSize()*4); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(buf.SegmentSize())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scratch := buf.GetBuffer()
+		if err := buf.Get(*scratch, 3); err != nil {
+			b.Fatal(err)
+		}
+		buf.PutBuffer(scratch)
+	}
+}
+
+func BenchmarkCursor_ReadNext(b *testing.B) {
+	buf := NewDefault()
+	if _, err := io.CopyN(buf, Rand(), buf.SegmentSize()*4); err != nil {
+		b.Fatal(err)
+	}
+	c := buf.NewCursor(3)
+	defer c.Close()
+	b.ReportAllocs()
+	b.SetBytes(buf.SegmentSize())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.SetID(3)
+		if _, err := c.ReadNext(io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package player
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Governor shrinks retention of buffers under memory pressure and
+// restores it afterwards. When memory usage exceeds Limit, segment count
+// of every buffer is halved (down to one segment) and shed segments are
+// collected; when usage drops below 80% of Limit, counts are doubled
+// back up to original ones.
+type Governor struct {
+	Buffers []*Buffer
+	Limit   uint64 // soft limit of memory usage in bytes
+	// Usage returns current memory usage, default is heap size from
+	// runtime.ReadMemStats.
+	Usage func() uint64
+	// Logger receives reports of shed and restored retention.
+	Logger Logger
+
+	mux      sync.Mutex
+	original map[*Buffer]int64
+}
+
+// heapUsage returns allocated heap size.
+func heapUsage() uint64 {
+	var s runtime.MemStats
+	runtime.ReadMemStats(&s)
+	return s.HeapAlloc
+}
+
+// Check compares memory usage with limit once and adjusts retention.
+// Returns count of segments shed by this check.
+func (g *Governor) Check() int64 {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	usage, log := g.Usage, g.Logger
+	if usage == nil {
+		usage = heapUsage
+	}
+	if log == nil {
+		log = noopLogger{}
+	}
+	if g.original == nil {
+		g.original = make(map[*Buffer]int64)
+		for _, b := range g.Buffers {
+			g.original[b] = b.Count()
+		}
+	}
+	used := usage()
+	var shed int64
+	switch {
+	case used > g.Limit:
+		for _, b := range g.Buffers {
+			count := b.Count()
+			if count <= 1 {
+				continue
+			}
+			// only whole segments are accounted, partial is kept
+			before := int64(b.Size()) / b.SegmentSize()
+			b.SetCount(count / 2)
+			if after := int64(b.Size()) / b.SegmentSize(); after < before {
+				shed += before - after
+			}
+		}
+		log.Printf("player: memory usage %d over limit %d, shed %d segments", used, g.Limit, shed)
+		if shed > 0 {
+			// collecting shed segments, so next check does not see
+			// them and shed again
+			runtime.GC()
+		}
+	case used < g.Limit/5*4:
+		for _, b := range g.Buffers {
+			count, original := b.Count(), g.original[b]
+			if count >= original {
+				continue
+			}
+			count *= 2
+			if count > original {
+				count = original
+			}
+			b.SetCount(count)
+			log.Printf("player: %s: retention restored to %d segments", b.key, count)
+		}
+	}
+	return shed
+}
+
+// Run calls Check every interval until ctx is done.
+func (g *Governor) Run(ctx context.Context, interval time.Duration) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "governor stopped")
+		case <-t.C:
+			g.Check()
+		}
+	}
+}
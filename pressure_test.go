@@ -0,0 +1,65 @@
+package player
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestGovernor(t *testing.T) {
+	b := New(Config{Count: 8, Segment: 4})
+	if _, err := b.Write(make([]byte, 4*8)); err != nil {
+		t.Fatal(err)
+	}
+	var usage uint64 = 200
+	g := &Governor{
+		Buffers: []*Buffer{b},
+		Limit:   100,
+		Usage: func() uint64 {
+			return usage
+		},
+	}
+	if shed := g.Check(); shed != 4 || b.Count() != 4 {
+		t.Error("bad shed", shed, b.Count())
+	}
+	if shed := g.Check(); shed != 2 || b.Count() != 2 {
+		t.Error("bad shed", shed, b.Count())
+	}
+	// between restore threshold and limit
+	usage = 90
+	if g.Check(); b.Count() != 2 {
+		t.Error("retention should be kept", b.Count())
+	}
+	usage = 10
+	if g.Check(); b.Count() != 4 {
+		t.Error("retention should be restored", b.Count())
+	}
+	if g.Check(); b.Count() != 8 {
+		t.Error("retention should be restored", b.Count())
+	}
+	if g.Check(); b.Count() != 8 {
+		t.Error("retention should not exceed original", b.Count())
+	}
+}
+
+func TestGovernor_HeapUsage(t *testing.T) {
+	const segment = 1 << 20
+	b := New(Config{Count: 64, Segment: segment})
+	for i := 0; i < 64; i++ {
+		if _, err := b.Write(make([]byte, segment)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runtime.GC()
+	before := heapUsage()
+	g := &Governor{Buffers: []*Buffer{b}, Limit: before - segment*16}
+	if shed := g.Check(); shed != 32 {
+		t.Fatal("bad shed", shed)
+	}
+	if used := heapUsage(); used > before-segment*24 {
+		t.Error("shed segments should be released", before, used)
+	}
+	// halving once is enough to get under limit
+	if shed := g.Check(); shed != 0 {
+		t.Error("should not shed again", shed, b.Count())
+	}
+}
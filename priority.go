@@ -0,0 +1,30 @@
+package player
+
+// Priority is class of Cursor consumer.
+type Priority int
+
+// Possible Priority values.
+const (
+	// PriorityLive is default priority, e.g. of live viewers.
+	PriorityLive Priority = iota
+	// PriorityBackground is priority of background consumers, e.g.
+	// archive jobs. Background cursors are ignored by backpressure
+	// policy, so they never block or delay writes and are never
+	// dropped, and do not prefetch from Archive.
+	PriorityBackground
+)
+
+// SetPriority sets priority class of cursor.
+func (c *Cursor) SetPriority(p Priority) {
+	c.b.l.Lock()
+	c.priority = p
+	c.b.broadcast() // writer may be blocked by this cursor
+	c.b.l.Unlock()
+}
+
+// Priority returns priority class of cursor.
+func (c *Cursor) Priority() Priority {
+	c.b.l.RLock()
+	defer c.b.l.RUnlock()
+	return c.priority
+}
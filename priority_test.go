@@ -0,0 +1,33 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCursor_Priority(t *testing.T) {
+	b := New(Config{
+		Count:        2,
+		Segment:      512,
+		Backpressure: BackpressureBlock,
+	})
+	c := b.NewCursor(0)
+	defer c.Close()
+	if c.Priority() != PriorityLive {
+		t.Error("default priority should be live")
+	}
+	c.SetPriority(PriorityBackground)
+	buf := make([]byte, 512*2)
+	if _, err := b.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	// background cursor should not block eviction
+	if _, err := b.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ReadNext(new(bytes.Buffer)); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
@@ -0,0 +1,115 @@
+package player
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBadRecording indicates invalid or unsupported ingest recording.
+const ErrBadRecording Error = "bad recording"
+
+// recording format constants.
+const (
+	recordingMagic   = "PLRC"
+	recordingVersion = 1
+)
+
+// Recorder is io.Writer that writes data to Buffer, recording every write
+// with its offset from start of recording, so ingest can be reproduced
+// later by Replay. Recording failure does not affect writes to Buffer:
+// it is logged and recording stops.
+type Recorder struct {
+	b     *Buffer
+	mux   sync.Mutex
+	e     *encoder
+	start time.Time
+}
+
+// NewRecorder writes recording header to w and returns Recorder
+// that writes to b.
+func NewRecorder(b *Buffer, w io.Writer) (*Recorder, error) {
+	r := &Recorder{
+		b:     b,
+		e:     &encoder{w: w},
+		start: b.clock.Now(),
+	}
+	_, r.e.err = io.WriteString(w, recordingMagic)
+	r.e.int(recordingVersion)
+	if r.e.err != nil {
+		return nil, errors.Wrap(r.e.err, "failed to write header")
+	}
+	return r, nil
+}
+
+// Write records p and writes it to Buffer.
+func (r *Recorder) Write(p []byte) (int, error) {
+	r.mux.Lock()
+	if r.e.err == nil {
+		r.e.int(int64(r.b.clock.Now().Sub(r.start)))
+		r.e.bytes(p)
+		if r.e.err != nil {
+			r.b.log.Printf("player: %s: recording stopped: %v", r.b.key, r.e.err)
+		}
+	}
+	r.mux.Unlock()
+	return r.b.Write(p)
+}
+
+// Replay writes data recorded by Recorder from r to b, preserving
+// original timing scaled by speed, e.g. speed 2 replays twice as fast.
+// Zero speed replays without delays.
+func Replay(ctx context.Context, r io.Reader, b *Buffer, speed float64) error {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(recordingMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != recordingMagic {
+		return errors.Wrap(ErrBadRecording, "bad magic")
+	}
+	d := &decoder{r: br}
+	if v := d.int(); d.err == nil && v != recordingVersion {
+		return errors.Wrapf(ErrBadRecording, "unsupported version %d", v)
+	}
+	if d.err != nil {
+		return errors.Wrap(d.err, "failed to read header")
+	}
+	start := b.clock.Now()
+	for {
+		if _, err := br.Peek(1); err == io.EOF {
+			return nil
+		}
+		offset := time.Duration(d.int())
+		data := d.bytes()
+		if d.err != nil {
+			return errors.Wrap(d.err, "failed to read record")
+		}
+		if speed > 0 {
+			release := start.Add(time.Duration(float64(offset) / speed))
+			if err := sleepUntil(ctx, b.clock, release); err != nil {
+				return errors.Wrap(err, "failed to replay")
+			}
+		}
+		if _, err := b.WriteContext(ctx, data); err != nil {
+			return errors.Wrap(err, "failed to replay")
+		}
+	}
+}
+
+// sleepUntil blocks until clock reaches t or ctx is done.
+func sleepUntil(ctx context.Context, c Clock, t time.Time) error {
+	d := t.Sub(c.Now())
+	if d <= 0 {
+		return nil
+	}
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestRecorder(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 4, Clock: c})
+	rec := new(bytes.Buffer)
+	r, err := NewRecorder(b, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range [][]byte{{1, 1}, {1, 1, 2, 2}, {2, 2}} {
+		c.Advance(time.Second * time.Duration(i))
+		if _, err := r.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replayed := New(Config{Count: 4, Segment: 4, Clock: c})
+	done := make(chan error, 1)
+	go func() {
+		done <- Replay(context.Background(), bytes.NewReader(rec.Bytes()), replayed, 2)
+	}()
+	// second write is recorded at 1s, replayed at 0.5s
+	waitTimers(t, c, 1)
+	if replayed.Size() != 2 {
+		t.Error("first write should be replayed immediately", replayed.Size())
+	}
+	c.Advance(time.Millisecond * 500)
+	waitTimers(t, c, 1)
+	if replayed.Size() != 6 {
+		t.Error("second write should be replayed", replayed.Size())
+	}
+	c.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if err := replayed.Get(buf, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{2, 2, 2, 2}) {
+		t.Error("bad segment", buf)
+	}
+
+	if err := Replay(context.Background(), bytes.NewReader([]byte("garbage")), replayed, 0); errors.Cause(err) != ErrBadRecording {
+		t.Error(err, "should be", ErrBadRecording)
+	}
+	truncated := rec.Bytes()[:rec.Len()-1]
+	if err := Replay(context.Background(), bytes.NewReader(truncated), New(Config{Count: 4, Segment: 4}), 0); err == nil {
+		t.Error("should fail on truncated recording")
+	}
+}
@@ -0,0 +1,83 @@
+package player
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Redundant merges ingest of redundant publishers of the same stream,
+// e.g. primary and backup encoders. Only active publisher is written to
+// Buffer; when it does not write within timeout, the next publisher that
+// writes becomes active. Each publisher is written to Buffer by whole
+// segments, so switch happens at segment boundary and first segment
+// after switch is marked as discontinuity.
+type Redundant struct {
+	b       *Buffer
+	timeout time.Duration
+	mux     sync.Mutex
+	active  int
+	last    []time.Time // time of last write of each publisher
+	partial [][]byte    // trailing partial segment of each publisher
+}
+
+// NewRedundant creates Redundant for n publishers. First publisher is
+// active initially.
+func (b *Buffer) NewRedundant(n int, timeout time.Duration) *Redundant {
+	return &Redundant{
+		b:       b,
+		timeout: timeout,
+		last:    make([]time.Time, n),
+		partial: make([][]byte, n),
+	}
+}
+
+// Input returns writer for publisher i.
+func (r *Redundant) Input(i int) io.Writer {
+	return redundantInput{r: r, i: i}
+}
+
+// Active returns index of active publisher.
+func (r *Redundant) Active() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.active
+}
+
+type redundantInput struct {
+	r *Redundant
+	i int
+}
+
+func (in redundantInput) Write(p []byte) (int, error) {
+	return in.r.write(in.i, p)
+}
+
+// write handles write of p by publisher i.
+func (r *Redundant) write(i int, p []byte) (int, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	now := r.b.clock.Now()
+	r.last[i] = now
+	if prev := r.last[r.active]; i != r.active && now.Sub(prev) > r.timeout {
+		if !prev.IsZero() {
+			r.b.log.Printf("player: %s: switched ingest from publisher %d to %d", r.b.key, r.active, i)
+			r.b.l.Lock()
+			r.b.addDiscontinuity(r.b.nextID())
+			r.b.l.Unlock()
+		}
+		r.active = i
+	}
+
+	size := r.b.SegmentSize()
+	data := append(r.partial[i], p...)
+	whole := int64(len(data)) / size * size
+	r.partial[i] = append(r.partial[i][:0:0], data[whole:]...)
+	if i != r.active || whole == 0 {
+		return len(p), nil
+	}
+	if _, err := r.b.Write(data[:whole]); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,49 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRedundant(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 8, Segment: 4, Clock: c})
+	r := b.NewRedundant(2, time.Second)
+	primary, backup := r.Input(0), r.Input(1)
+	write := func(w interface{ Write([]byte) (int, error) }, p ...byte) {
+		t.Helper()
+		if _, err := w.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(primary, 0, 0, 0, 0, 1, 1)
+	write(backup, 9, 9, 9, 9, 9, 9, 9, 9)
+	c.Advance(time.Millisecond * 500)
+	write(primary, 1, 1)
+	// primary stalls
+	c.Advance(time.Millisecond * 1500)
+	write(backup, 2, 2, 2, 2)
+	if r.Active() != 1 {
+		t.Fatal("backup should be active")
+	}
+	// primary resumes, but backup is alive
+	write(primary, 7, 7, 7, 7)
+
+	if _, last, _ := b.Window(); last != 2 {
+		t.Fatal("bad last id", last)
+	}
+	buf := make([]byte, 4)
+	for id, want := range [][]byte{{0, 0, 0, 0}, {1, 1, 1, 1}, {2, 2, 2, 2}} {
+		if err := b.Get(buf, int64(id)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, want) {
+			t.Error("bad segment", id, buf)
+		}
+	}
+	if disc, err := b.Discontinuity(2); err != nil || !disc {
+		t.Error("segment after switch should be discontinuity", err)
+	}
+}
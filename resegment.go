@@ -0,0 +1,81 @@
+package player
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBadSegment indicates invalid segment size.
+const ErrBadSegment Error = "bad segment size"
+
+// Resegment rewrites current window into segments of newSize, preserving
+// stream byte order and window capacity in bytes. Segments are renumbered
+// starting from the first id that was never used, so ids issued before
+// Resegment are never reused for other data. Metadata, discontinuity
+// marks and cursors are moved to new segments that contain their byte
+// positions.
+func (b *Buffer) Resegment(newSize int64) error {
+	if newSize <= 0 {
+		return errors.Wrap(ErrBadSegment, "failed to resegment")
+	}
+
+	b.l.Lock()
+	oldSize, oldFirst, oldNext := b.segment, b.firstID, b.nextID()
+	newFirst := oldNext
+	// remap returns new id of segment containing first byte of
+	// old segment with provided id.
+	remap := func(id int64) int64 {
+		if id < oldFirst {
+			id = oldFirst
+		}
+		pos := (id - oldFirst) * oldSize
+		if pos > int64(len(b.data)) {
+			pos = int64(len(b.data))
+		}
+		return newFirst + pos/newSize
+	}
+
+	meta := make(map[int64][][]byte, len(b.meta))
+	for id, events := range b.meta {
+		newID := remap(id)
+		meta[newID] = append(meta[newID], events...)
+	}
+	disc := make(map[int64]struct{}, len(b.disc))
+	for id := range b.disc {
+		disc[remap(id)] = struct{}{}
+	}
+	for c := range b.cursors {
+		c.id = remap(c.id)
+	}
+	now := b.clock.Now()
+	count := int64(len(b.data)) / newSize
+	times := make([]time.Time, count)
+	for i := range times {
+		// completed when old segment containing its last byte was completed
+		old := ((int64(i)+1)*newSize - 1) / oldSize
+		if old < int64(len(b.times)) {
+			times[i] = b.times[old]
+		} else {
+			times[i] = now
+		}
+	}
+
+	b.maxCount = b.maxCount * oldSize / newSize
+	if b.maxCount < 1 {
+		b.maxCount = 1
+	}
+	b.segment = newSize
+	b.firstID = newFirst
+	b.meta = meta
+	b.disc = disc
+	b.times = times
+	b.count = count
+	b.lastID = b.firstID + b.count - 1
+	b.generation++
+	from, to := b.evict()
+	b.broadcast()
+	b.l.Unlock()
+	b.onEvict(from, to)
+	return nil
+}
@@ -0,0 +1,87 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Resegment(t *testing.T) {
+	r := Rand()
+	b := New(Config{
+		Count:   4,
+		Segment: 512,
+	})
+	data := new(bytes.Buffer)
+	if _, err := io.CopyN(io.MultiWriter(b, data), r, 512*3+100); err != nil {
+		t.Error(err)
+	}
+	c := b.NewCursor(1)
+	defer c.Close()
+	b.WriteMeta([]byte("pending"))
+	if err := b.Resegment(0); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+	if err := b.Resegment(256); err != nil {
+		t.Fatal(err)
+	}
+	if b.SegmentSize() != 256 || b.Count() != 8 {
+		t.Error("bad size or count", b.SegmentSize(), b.Count())
+	}
+	if b.FirstID() != 3 || b.LastID() != 8 {
+		t.Error("bad window", b.FirstID(), b.LastID())
+	}
+	if c.ID() != 5 {
+		t.Error("bad cursor position", c.ID())
+	}
+	if offset, _, err := b.ByteRange(5); err != nil || offset != 512 {
+		t.Error("bad offset", offset, err)
+	}
+	got := new(bytes.Buffer)
+	buf := make([]byte, 256)
+	for id := b.FirstID(); id <= b.LastID(); id++ {
+		if err := b.Get(buf, id); err != nil {
+			t.Error(err)
+		}
+		got.Write(buf)
+	}
+	if !bytes.Equal(got.Bytes(), data.Bytes()[:got.Len()]) {
+		t.Error("byte order should be preserved")
+	}
+
+	// completing pending segment
+	if _, err := b.Write(buf[:156]); err != nil {
+		t.Error(err)
+	}
+	if meta, err := b.Meta(9); err != nil || len(meta) != 1 {
+		t.Error("metadata should move with its segment", err, meta)
+	}
+}
+
+func TestBuffer_ResegmentDiscontinuity(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	if _, err := b.Write(make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	b.l.Lock()
+	b.addDiscontinuity(1)
+	b.l.Unlock()
+	if _, err := b.Write(make([]byte, 4)); err != nil {
+		t.Fatal(err)
+	}
+	// segment 1 starts at byte 4, which is new segment 3+2
+	if err := b.Resegment(2); err != nil {
+		t.Fatal(err)
+	}
+	for id := b.FirstID(); id <= b.LastID(); id++ {
+		disc, err := b.Discontinuity(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if disc != (id == 5) {
+			t.Error("bad discontinuity of", id, disc)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package player
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// IDAt returns id of segment that contains timestamp t, i.e. the first
+// segment that was completed at or after t.
+func (b *Buffer) IDAt(t time.Time) (int64, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.idAt(t)
+}
+
+// SeekReader creates and registers cursor that starts reading from
+// segment containing timestamp t, e.g. to jump back two minutes from
+// live. Cursor should be closed after use.
+func (b *Buffer) SeekReader(t time.Time) (*Cursor, error) {
+	b.l.Lock()
+	defer b.l.Unlock()
+	id, err := b.idAt(t)
+	if err != nil {
+		return nil, err
+	}
+	return b.newCursor(id), nil
+}
+
+// idAt returns id of segment that contains timestamp t. No locks.
+func (b *Buffer) idAt(t time.Time) (int64, error) {
+	if len(b.times) == 0 {
+		return 0, errors.Wrap(ErrEmpty, "bad time")
+	}
+	if !b.evicted.IsZero() && !t.After(b.evicted) {
+		// segment containing t is already evicted
+		return 0, errors.Wrap(ErrMiss, "bad time")
+	}
+	i := sort.Search(len(b.times), func(i int) bool {
+		return !b.times[i].Before(t)
+	})
+	if i == len(b.times) {
+		// segment containing t is not written yet
+		return 0, errors.Wrap(ErrMiss, "bad time")
+	}
+	return b.firstID + int64(i), nil
+}
+
+// EpochID returns id of segment with duration d that contains t when
+// segments are numbered from unix epoch.
+func EpochID(t time.Time, d time.Duration) int64 {
+	return t.UnixNano() / int64(d)
+}
+
+// Completed returns completion time of segment with provided id.
+func (b *Buffer) Completed(id int64) (time.Time, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if err := b.acquireID(id); err != nil {
+		return time.Time{}, errors.Wrap(err, "bad id")
+	}
+	return b.times[id-b.firstID], nil
+}
+
+// IDAtOffset returns id of segment that contains logical byte offset from
+// the start of stream and position of offset within that segment. It is
+// inverse of ByteRange, so raw byte consumers can resume from offset.
+// Segments have fixed size, so no index is kept.
+func (b *Buffer) IDAtOffset(offset int64) (id, pos int64, err error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if offset < b.offset {
+		// segment containing offset is already evicted
+		return 0, 0, errors.Wrap(ErrMiss, "bad offset")
+	}
+	id = b.firstID + (offset-b.offset)/b.segment
+	if err := b.acquireID(id); err != nil {
+		return 0, 0, errors.Wrap(err, "bad offset")
+	}
+	return id, (offset - b.offset) % b.segment, nil
+}
@@ -0,0 +1,148 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_IDAt(t *testing.T) {
+	b := New(Config{
+		Count:   2,
+		Segment: 512,
+	})
+	if _, err := b.IDAt(time.Now()); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	buf := make([]byte, 512)
+	start := time.Now()
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	time.Sleep(time.Millisecond)
+	mid := time.Now()
+	time.Sleep(time.Millisecond)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if id, err := b.IDAt(start); err != nil || id != 0 {
+		t.Error(err, id)
+	}
+	if id, err := b.IDAt(mid); err != nil || id != 1 {
+		t.Error(err, id)
+	}
+	if _, err := b.IDAt(time.Now().Add(time.Second)); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+
+	// evicting segment 0
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if _, err := b.IDAt(start); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if id, err := b.IDAt(mid); err != nil || id != 1 {
+		t.Error(err, id)
+	}
+}
+
+func TestBuffer_SeekReader(t *testing.T) {
+	c := NewFakeClock(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	b := New(Config{Count: 4, Segment: 2, Clock: c})
+	start := c.Now()
+	if _, err := b.SeekReader(start); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	for i := 0; i < 3; i++ {
+		c.Advance(time.Second)
+		if _, err := b.Write([]byte{byte(i), byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cursor, err := b.SeekReader(start.Add(1500 * time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cursor.Close()
+	buf := new(bytes.Buffer)
+	if _, err := cursor.ReadNext(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{1, 1}) {
+		t.Error("bad segment", buf.Bytes())
+	}
+	if _, err := b.SeekReader(start.Add(time.Minute)); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func TestBuffer_Completed(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4})
+	if _, err := b.Completed(0); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	start := time.Now()
+	if _, err := b.Write(make([]byte, 6)); err != nil {
+		t.Fatal(err)
+	}
+	if at, err := b.Completed(0); err != nil || at.Before(start) {
+		t.Error(err, at)
+	}
+	if _, err := b.Completed(1); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func TestBuffer_IDAtOffset(t *testing.T) {
+	b := New(Config{Count: 3, Segment: 4, Start: 10})
+	if _, _, err := b.IDAtOffset(0); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := b.Write(make([]byte, 4)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// segments 11..13 are in window, offset of 11 is 4
+	if _, _, err := b.IDAtOffset(3); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	id, pos, err := b.IDAtOffset(9)
+	if err != nil || id != 12 || pos != 1 {
+		t.Error("bad position", id, pos, err)
+	}
+	if offset, _, err := b.ByteRange(id); err != nil || offset+pos != 9 {
+		t.Error("should be inverse of ByteRange", offset, err)
+	}
+	if _, _, err := b.IDAtOffset(16); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func TestEpochStart(t *testing.T) {
+	now := time.Unix(1000, 500)
+	c := NewFakeClock(now)
+	cfg := Config{
+		Count:      4,
+		Segment:    4,
+		Duration:   time.Second * 2,
+		EpochStart: true,
+		Clock:      c,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	b := New(cfg)
+	if first := b.FirstID(); first != 500 {
+		t.Error("bad first id", first)
+	}
+	if id := EpochID(now.Add(time.Second*2), cfg.Duration); id != 501 {
+		t.Error("bad epoch id", id)
+	}
+	if err := (Config{EpochStart: true}).Validate(); errors.Cause(err) != ErrBadConfig {
+		t.Error(err, "should be", ErrBadConfig)
+	}
+}
@@ -0,0 +1,70 @@
+package player
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// SeekableReader returns io.ReadSeeker over complete segments of Buffer
+// window, e.g. for serving it via http.ServeContent. Offset 0 is the
+// start of window at the time of call, and end of reader follows the last
+// complete segment, so io.EOF is returned at the live edge and reading
+// can be resumed after new segments are written. Reading of already
+// evicted bytes returns ErrMiss. Like SegmentReader, it reads stored
+// data, without transforms.
+func (b *Buffer) SeekableReader() io.ReadSeeker {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return &windowReader{b: b, base: b.offset}
+}
+
+// windowReader is io.ReadSeeker over Buffer window.
+type windowReader struct {
+	b    *Buffer
+	base int64 // logical offset of reader start
+	pos  int64 // position relative to base
+}
+
+func (r *windowReader) Read(p []byte) (int, error) {
+	b := r.b
+	b.l.RLock()
+	offset := r.base + r.pos
+	if offset < b.offset {
+		b.l.RUnlock()
+		return 0, errors.Wrap(ErrMiss, "bad offset")
+	}
+	id := b.firstID + (offset-b.offset)/b.segment
+	if id >= b.nextID() {
+		b.l.RUnlock()
+		return 0, io.EOF
+	}
+	n := copy(p, b.data[offset-b.offset:b.segment*(id-b.firstID+1)])
+	b.l.RUnlock()
+	r.pos += int64(n)
+	atomic.AddInt64(&b.stats.BytesOut, int64(n))
+	b.traceGet("SeekableReader", id, n, nil)
+	return n, nil
+}
+
+func (r *windowReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		b := r.b
+		b.l.RLock()
+		end := b.offset + (b.nextID()-b.firstID)*b.segment
+		b.l.RUnlock()
+		offset += end - r.base
+	default:
+		return 0, errors.New("bad whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.pos = offset
+	return offset, nil
+}
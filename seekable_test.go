@@ -0,0 +1,66 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_SeekableReader(t *testing.T) {
+	b := New(Config{Count: 3, Segment: 4})
+	if _, err := b.Write([]byte{0, 1, 2, 3, 4, 5, 6, 7, 8}); err != nil {
+		t.Fatal(err)
+	}
+	rs := b.SeekableReader()
+	if end, err := rs.Seek(0, io.SeekEnd); err != nil || end != 8 {
+		t.Error("bad end", end, err)
+	}
+	if _, err := rs.Seek(2, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, []byte{2, 3, 4, 5, 6, 7}) {
+		t.Error("bad data", data)
+	}
+	// reading resumes after new segment
+	if _, err := b.Write([]byte{9, 10, 11, 12, 13, 14, 15, 16}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err = io.ReadAll(rs); err != nil || !bytes.Equal(data, []byte{8, 9, 10, 11, 12, 13, 14, 15}) {
+		t.Error("bad data", data, err)
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rs.Read(make([]byte, 1)); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if _, err := rs.Seek(-1, io.SeekStart); err == nil {
+		t.Error("negative position should fail")
+	}
+}
+
+func TestBuffer_SeekableReaderServeContent(t *testing.T) {
+	b := New(Config{Count: 3, Segment: 4})
+	if _, err := b.Write([]byte{0, 1, 2, 3, 4, 5, 6, 7}); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Range", "bytes=3-5")
+	rec := httptest.NewRecorder()
+	http.ServeContent(rec, req, "", time.Time{}, b.SeekableReader())
+	if rec.Code != http.StatusPartialContent {
+		t.Fatal("bad status", rec.Code)
+	}
+	if !bytes.Equal(rec.Body.Bytes(), []byte{3, 4, 5}) {
+		t.Error("bad body", rec.Body.Bytes())
+	}
+}
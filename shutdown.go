@@ -0,0 +1,54 @@
+package player
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ErrClosed means that Buffer is shut down.
+const ErrClosed Error = "buffer is closed"
+
+// Shutdown stops accepting writes and waits until every cursor reads
+// all completed segments or is closed, so clients can finish segments
+// already in flight. Trailing partial segment is never completed. Pending
+// WaitForID and Broadcast calls return ErrClosed once there is nothing
+// left to read. Buffer stays readable after Shutdown.
+func (b *Buffer) Shutdown(ctx context.Context) error {
+	b.l.Lock()
+	closing := !b.closed
+	b.closed = true
+	b.broadcast()
+	if closing {
+		b.l.Unlock()
+		b.emit(Event{Kind: EventStreamClosed, ID: -1})
+		b.l.Lock()
+	}
+	for b.draining() {
+		changed := b.changed()
+		b.waiting++
+		b.l.Unlock()
+		select {
+		case <-ctx.Done():
+			b.l.Lock()
+			b.waiting--
+			b.l.Unlock()
+			return errors.Wrap(ctx.Err(), "failed to drain")
+		case <-changed:
+		}
+		b.l.Lock()
+		b.waiting--
+	}
+	b.l.Unlock()
+	return nil
+}
+
+// draining reports whether any cursor has unread segments. No locks.
+func (b *Buffer) draining() bool {
+	for c := range b.cursors {
+		if c.lag() > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Shutdown(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	c := b.NewCursor(0)
+	if _, err := b.Write([]byte{0, 0, 0, 0, 1, 1, 1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-done:
+		t.Fatal("shutdown should wait for cursor", err)
+	case <-time.After(time.Millisecond * 20):
+	}
+	if _, err := b.Write([]byte{2, 2, 2, 2}); errors.Cause(err) != ErrClosed {
+		t.Error(err, "should be", ErrClosed)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.ReadNext(new(bytes.Buffer)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+	if err := b.WaitForID(context.Background(), 2); errors.Cause(err) != ErrClosed {
+		t.Error(err, "should be", ErrClosed)
+	}
+	if err := b.Get(make([]byte, 4), 1); err != nil {
+		t.Error("should be readable", err)
+	}
+}
+
+func TestBuffer_ShutdownDeadline(t *testing.T) {
+	b := New(Config{Count: 4, Segment: 4})
+	b.NewCursor(0)
+	if _, err := b.Write([]byte{0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+	defer cancel()
+	if err := b.Shutdown(ctx); errors.Cause(err) != context.DeadlineExceeded {
+		t.Error(err, "should be", context.DeadlineExceeded)
+	}
+}
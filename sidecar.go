@@ -0,0 +1,72 @@
+package player
+
+import (
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Sidecar is companion event track for Buffer, e.g. chat messages,
+// telemetry or captions, stored in Buffer metadata and keyed to segments.
+// Events are encoded with Codec, e.g. as JSON or CBOR.
+type Sidecar[T any] struct {
+	b     *Buffer
+	codec Codec[T]
+}
+
+// NewSidecar creates event track for b.
+func NewSidecar[T any](b *Buffer, codec Codec[T]) *Sidecar[T] {
+	return &Sidecar[T]{
+		b:     b,
+		codec: codec,
+	}
+}
+
+// Add attaches event to the segment that is currently being written.
+func (s *Sidecar[T]) Add(v T) (int64, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to marshal")
+	}
+	return s.b.WriteMeta(data), nil
+}
+
+// AddAt attaches event to the segment that contains timestamp t.
+func (s *Sidecar[T]) AddAt(t time.Time, v T) (int64, error) {
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to marshal")
+	}
+	return s.b.WriteMetaAt(t, data)
+}
+
+// Events returns events attached to segment with provided id.
+func (s *Sidecar[T]) Events(id int64) ([]T, error) {
+	meta, err := s.b.Meta(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.decode(meta)
+}
+
+// ReadNext writes next segment of c to w and returns events
+// that belong to that segment.
+func (s *Sidecar[T]) ReadNext(c *Cursor, w io.Writer) (int, []T, error) {
+	n, meta, err := c.ReadNextMeta(w)
+	if err != nil {
+		return n, nil, err
+	}
+	events, err := s.decode(meta)
+	return n, events, err
+}
+
+func (s *Sidecar[T]) decode(meta [][]byte) ([]T, error) {
+	events := make([]T, len(meta))
+	for i, data := range meta {
+		if err := s.codec.Unmarshal(data, &events[i]); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal")
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,50 @@
+package player
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+type chatMessage struct {
+	User string
+	Text string
+}
+
+func TestSidecar(t *testing.T) {
+	b := New(Config{
+		Count:   4,
+		Segment: 512,
+	})
+	s := NewSidecar[chatMessage](b, jsonCodec[chatMessage]{})
+	buf := make([]byte, 512)
+	start := time.Now()
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	time.Sleep(time.Millisecond)
+	if id, err := s.Add(chatMessage{User: "a", Text: "live"}); err != nil || id != 1 {
+		t.Error(err, id)
+	}
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if id, err := s.AddAt(start, chatMessage{User: "b", Text: "late"}); err != nil || id != 0 {
+		t.Error(err, id)
+	}
+	events, err := s.Events(0)
+	if err != nil || len(events) != 1 || events[0].Text != "late" {
+		t.Error("bad events", events, err)
+	}
+
+	c := b.NewCursor(1)
+	defer c.Close()
+	out := new(bytes.Buffer)
+	n, events, err := s.ReadNext(c, out)
+	if err != nil || n != 512 {
+		t.Error(err, n)
+	}
+	if len(events) != 1 || events[0].User != "a" {
+		t.Error("bad events", events)
+	}
+}
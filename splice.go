@@ -0,0 +1,40 @@
+package player
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// SpliceFrom cuts Buffer over to other source: trailing partial segment
+// is dropped, next segment is marked as discontinuity, and segments of
+// other starting from atID are written to Buffer until ctx is done or
+// write fails. To switch to another source, cancel ctx of current
+// splice and wait for it to return before starting the next one.
+func (b *Buffer) SpliceFrom(ctx context.Context, other *Buffer, atID int64) error {
+	b.sl.Lock()
+	b.l.Lock()
+	if partial := b.dropPartial(); partial > 0 {
+		b.log.Printf("player: %s: dropped %d bytes of partial segment for splice", b.key, partial)
+	}
+	b.addDiscontinuity(b.nextID())
+	b.l.Unlock()
+	b.sl.Unlock()
+
+	c := other.NewCursor(atID)
+	if err := other.streamTo(ctx, c, contextWriter{ctx: ctx, b: b}); err != nil {
+		return errors.Wrap(err, "splice stopped")
+	}
+	return nil
+}
+
+// contextWriter writes to Buffer by WriteContext, so write blocked by
+// backpressure is interrupted when ctx is done.
+type contextWriter struct {
+	ctx context.Context
+	b   *Buffer
+}
+
+func (w contextWriter) Write(p []byte) (int, error) {
+	return w.b.WriteContext(w.ctx, p)
+}
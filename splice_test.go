@@ -0,0 +1,126 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_SpliceFrom(t *testing.T) {
+	a := New(Config{Count: 4, Segment: 2})
+	b := New(Config{Count: 4, Segment: 2})
+	out := New(Config{Count: 8, Segment: 2})
+	if _, err := a.Write([]byte{1, 1, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Write([]byte{7, 7, 8, 8, 9, 9}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Write([]byte{0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	next := func() int64 {
+		_, last, _ := out.Window()
+		return last + 1
+	}
+	splice := func(src *Buffer, at int64, segments int64) {
+		t.Helper()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- out.SpliceFrom(ctx, src, at)
+		}()
+		target := next() + segments
+		deadline := time.Now().Add(time.Second)
+		for next() < target {
+			if time.Now().After(deadline) {
+				t.Fatal("segments should be spliced")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		cancel()
+		if err := <-done; err == nil {
+			t.Error("splice should be cancelled")
+		}
+	}
+	splice(a, 0, 2)
+	splice(b, 1, 2)
+
+	var got []byte
+	buf := make([]byte, 2)
+	for id := int64(0); id < 5; id++ {
+		if err := out.Get(buf, id); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, buf...)
+	}
+	if !bytes.Equal(got, []byte{0, 0, 1, 1, 2, 2, 8, 8, 9, 9}) {
+		t.Error("bad output", got)
+	}
+	for id, want := range []bool{false, true, false, true, false} {
+		if disc, err := out.Discontinuity(int64(id)); err != nil || disc != want {
+			t.Error("bad discontinuity", id, disc, err)
+		}
+	}
+}
+
+func TestBuffer_SpliceFromCancelBlocked(t *testing.T) {
+	src := New(Config{Count: 4, Segment: 2})
+	if _, err := src.Write([]byte{1, 1}); err != nil {
+		t.Fatal(err)
+	}
+	out := New(Config{Count: 1, Segment: 2, Backpressure: BackpressureBlock})
+	c := out.NewCursor(0)
+	defer c.Close()
+	if _, err := out.Write([]byte{0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	// next write evicts segment that is not read by c
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- out.SpliceFrom(ctx, src, 0)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if errors.Cause(err) != context.Canceled {
+			t.Error(err, "should be", context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked write should be interrupted by cancel")
+	}
+}
+
+func TestBuffer_SpliceFromStaged(t *testing.T) {
+	src := New(Config{Count: 4, Segment: 4})
+	out := New(Config{Count: 4, Segment: 4, Coalesce: true})
+	if _, err := src.Write([]byte("WXYZ")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := out.Write([]byte("ab")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- out.SpliceFrom(ctx, src, 0)
+	}()
+	if err := out.WaitForID(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	<-done
+	buf := make([]byte, 4)
+	if err := out.Get(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "WXYZ" {
+		t.Errorf("staged data should be dropped, got %q", buf)
+	}
+}
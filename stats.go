@@ -0,0 +1,68 @@
+package player
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is snapshot of Buffer counters.
+type Stats struct {
+	Writes    int64 // count of successful writes
+	BytesIn   int64 // total bytes written
+	BytesOut  int64 // total bytes of segments read
+	MaxWrite  int64 // maximum observed write size
+	Evictions int64 // count of evicted segments
+	Hits      int64 // count of successful id lookups
+	Misses    int64 // count of failed id lookups
+	Capacity  int64 // capacity of internal buffer in bytes
+	AvgWrite  int64 // average write size
+
+	// Throughput over segments in window, zero if there are less than
+	// two of them. Segments have fixed size, so ingest rate is reflected
+	// by intervals between segment completions.
+	Bitrate     float64       // ingest bitrate, bits per second
+	Cadence     time.Duration // average interval between segments
+	MaxInterval time.Duration // maximum interval between segments
+}
+
+// Stats returns current counters of Buffer.
+func (b *Buffer) Stats() Stats {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	s := Stats{
+		Writes:    b.stats.Writes,
+		BytesIn:   b.stats.BytesIn,
+		MaxWrite:  b.stats.MaxWrite,
+		Evictions: b.stats.Evictions,
+		// updated by concurrent readers
+		BytesOut: atomic.LoadInt64(&b.stats.BytesOut),
+		Hits:     atomic.LoadInt64(&b.stats.Hits),
+		Misses:   atomic.LoadInt64(&b.stats.Misses),
+		Capacity: int64(cap(b.data)),
+	}
+	if s.Writes > 0 {
+		s.AvgWrite = s.BytesIn / s.Writes
+	}
+	if n := len(b.times); n > 1 {
+		elapsed := b.times[n-1].Sub(b.times[0])
+		if elapsed > 0 {
+			s.Bitrate = float64((n-1)*int(b.segment)*8) / elapsed.Seconds()
+		}
+		s.Cadence = elapsed / time.Duration(n-1)
+		for i := 1; i < n; i++ {
+			if d := b.times[i].Sub(b.times[i-1]); d > s.MaxInterval {
+				s.MaxInterval = d
+			}
+		}
+	}
+	return s
+}
+
+// Publish exports Buffer stats via expvar with provided name.
+// Like expvar.Publish, it panics if name is already registered.
+func (b *Buffer) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return b.Stats()
+	}))
+}
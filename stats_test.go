@@ -0,0 +1,77 @@
+package player
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestBuffer_Stats(t *testing.T) {
+	b := New(Config{
+		Count:   2,
+		Segment: 512,
+	})
+	buf := make([]byte, 512*2)
+	for i := 0; i < 2; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := b.Get(buf, 3); err != nil {
+		t.Error(err)
+	}
+	if err := b.Get(buf, 0); err == nil {
+		t.Error("should miss")
+	}
+	s := b.Stats()
+	if s.Writes != 2 || s.BytesIn != 512*4 || s.MaxWrite != 512*2 {
+		t.Errorf("bad write stats %+v", s)
+	}
+	if s.Evictions != 2 || s.Hits != 1 || s.Misses != 1 || s.BytesOut != 512 {
+		t.Errorf("bad read stats %+v", s)
+	}
+	if s.Capacity < 512*2 {
+		t.Errorf("bad capacity %+v", s)
+	}
+}
+
+func TestBuffer_StatsThroughput(t *testing.T) {
+	b := New(Config{
+		Count:   8,
+		Segment: 1000,
+	})
+	buf := make([]byte, 1000)
+	for i := 0; i < 3; i++ {
+		if _, err := b.Write(buf); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	s := b.Stats()
+	if s.AvgWrite != 1000 {
+		t.Error("bad average write", s.AvgWrite)
+	}
+	if s.Cadence < time.Millisecond*10 || s.MaxInterval < s.Cadence {
+		t.Error("bad cadence", s.Cadence, s.MaxInterval)
+	}
+	// 1000 bytes per at least 10ms
+	if s.Bitrate <= 0 || s.Bitrate > 8000*100 {
+		t.Error("bad bitrate", s.Bitrate)
+	}
+}
+
+func TestBuffer_Publish(t *testing.T) {
+	const name = "player_test_buffer"
+	if expvar.Get(name) == nil {
+		NewDefault().Publish(name)
+	}
+	v := expvar.Get(name)
+	if v == nil {
+		t.Fatal("not published")
+	}
+	var s Stats
+	if err := json.Unmarshal([]byte(v.String()), &s); err != nil {
+		t.Error(err)
+	}
+}
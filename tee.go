@@ -0,0 +1,105 @@
+package player
+
+import (
+	"io"
+	"sync"
+)
+
+// tee is writer that receives copy of every write to Buffer.
+type tee struct {
+	w      io.Writer
+	queue  chan []byte // nil for synchronous tee
+	done   chan struct{}
+	once   sync.Once
+	failed bool // write to w failed, guarded by tl for synchronous tee
+}
+
+// TeeTo copies every write to Buffer to w, e.g. to archive file, and
+// returns function that removes it. Data is copied as stored, after
+// write transforms, in order of stores.
+//
+// If queue is zero, w is written synchronously before Write returns, so
+// slow w slows down ingest; w must not write to Buffer. Otherwise up to
+// queue writes are buffered and written to w in background, and writes
+// that do not fit into full queue are dropped and logged. Remove waits
+// until queued writes are written to w.
+//
+// Tee is removed after first failed write to w.
+func (b *Buffer) TeeTo(w io.Writer, queue int) (remove func()) {
+	t := &tee{w: w, done: make(chan struct{})}
+	if queue > 0 {
+		t.queue = make(chan []byte, queue)
+		go b.runTee(t)
+	} else {
+		close(t.done)
+	}
+	b.tl.Lock()
+	b.tees = append(b.tees, t)
+	b.tl.Unlock()
+	return func() {
+		b.tl.Lock()
+		b.detach(t)
+		b.tl.Unlock()
+		<-t.done
+	}
+}
+
+// runTee writes queued data to w of asynchronous tee until it is
+// detached.
+func (b *Buffer) runTee(t *tee) {
+	defer close(t.done)
+	failed := false
+	for p := range t.queue {
+		if failed {
+			continue
+		}
+		if _, err := t.w.Write(p); err != nil {
+			b.log.Printf("player: %s: tee failed: %v", b.key, err)
+			failed = true
+			b.tl.Lock()
+			b.detach(t)
+			b.tl.Unlock()
+		}
+	}
+}
+
+// detach removes t from tees. Should be called with tl locked.
+func (b *Buffer) detach(t *tee) {
+	for i, v := range b.tees {
+		if v == t {
+			b.tees = append(b.tees[:i:i], b.tees[i+1:]...)
+			break
+		}
+	}
+	t.once.Do(func() {
+		if t.queue != nil {
+			close(t.queue)
+		}
+	})
+}
+
+// tee copies p to all tees and unlocks tl. Should be called with tl
+// locked, which is taken under write lock of Buffer, so tees receive
+// data in order of stores.
+func (b *Buffer) tee(p []byte) {
+	defer b.tl.Unlock()
+	for _, t := range b.tees {
+		if t.queue == nil {
+			if _, err := t.w.Write(p); err != nil {
+				b.log.Printf("player: %s: tee failed: %v", b.key, err)
+				t.failed = true
+			}
+			continue
+		}
+		select {
+		case t.queue <- append([]byte(nil), p...):
+		default:
+			b.log.Printf("player: %s: tee queue is full, dropped %d bytes", b.key, len(p))
+		}
+	}
+	for i := len(b.tees) - 1; i >= 0; i-- {
+		if t := b.tees[i]; t.failed {
+			b.detach(t)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package player
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("failed")
+}
+
+func TestBuffer_TeeTo(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4})
+	sync, async := new(bytes.Buffer), new(bytes.Buffer)
+	removeSync := b.TeeTo(sync, 0)
+	removeAsync := b.TeeTo(async, 16)
+	for _, p := range [][]byte{{0, 1, 2}, {3, 4, 5, 6, 7, 8}, {9}} {
+		if _, err := b.Write(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	if !bytes.Equal(sync.Bytes(), want) {
+		t.Error("bad synchronous tee", sync.Bytes())
+	}
+	removeSync()
+	removeAsync()
+	if !bytes.Equal(async.Bytes(), want) {
+		t.Error("bad asynchronous tee", async.Bytes())
+	}
+	if _, err := b.Write([]byte{10}); err != nil {
+		t.Fatal(err)
+	}
+	if sync.Len() != len(want) || async.Len() != len(want) {
+		t.Error("removed tee should not receive writes")
+	}
+}
+
+func TestBuffer_TeeToFlush(t *testing.T) {
+	b := New(Config{Count: 2, Segment: 4})
+	out := new(bytes.Buffer)
+	defer b.TeeTo(out, 0)()
+	if _, err := b.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Flush(9); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), []byte{1, 9, 9, 9}) {
+		t.Error("tee should receive padding", out.Bytes())
+	}
+}
+
+func TestBuffer_TeeToFailed(t *testing.T) {
+	out := new(bytes.Buffer)
+	b := New(Config{Count: 2, Segment: 4, Logger: log.New(out, "", 0)})
+	b.TeeTo(failingWriter{}, 0)
+	for i := 0; i < 2; i++ {
+		if _, err := b.Write([]byte{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := strings.Count(out.String(), "tee failed"); n != 1 {
+		t.Error("failed tee should be removed, got", n, "failures")
+	}
+}
+
+func TestBuffer_TeeToOverflow(t *testing.T) {
+	out := new(bytes.Buffer)
+	b := New(Config{Count: 2, Segment: 4, Logger: log.New(out, "", 0)})
+	r, w := io.Pipe()
+	remove := b.TeeTo(w, 1)
+	// first write is blocked in pipe, second is queued, third is dropped
+	for i := 0; i < 3; i++ {
+		if _, err := b.Write([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			// wait until first write is taken from queue
+			for len(b.tees[0].queue) > 0 {
+				runtime.Gosched()
+			}
+		}
+	}
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	remove()
+	w.Close()
+	if data := <-done; !bytes.Equal(data, []byte{0, 1}) {
+		t.Error("bad data", data)
+	}
+	if !strings.Contains(out.String(), "dropped 1 bytes") {
+		t.Error("drop should be logged:", out.String())
+	}
+}
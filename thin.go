@@ -0,0 +1,49 @@
+package player
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// Thin writes segments of Buffer, starting from the next one, to derived
+// Buffer dst until ctx is done, keeping only segments for which keep
+// returns true, e.g. every Nth segment or segments that start with
+// keyframe. Derived Buffer has own ids and retention, and should have the
+// same segment size. If dst is too slow, evicted segments are skipped.
+func (b *Buffer) Thin(ctx context.Context, dst *Buffer, keep func(id int64, seg []byte) bool) error {
+	if dst.SegmentSize() != b.SegmentSize() {
+		return errors.Wrap(ErrBadSegment, "failed to thin")
+	}
+	b.l.Lock()
+	c := b.newCursor(b.nextID())
+	b.l.Unlock()
+	return b.streamTo(ctx, c, thinWriter{ctx: ctx, c: c, dst: dst, keep: keep})
+}
+
+// EveryNth returns keep function for Thin that keeps segments with ids
+// divisible by n. Returns ErrBadConfig if n is not positive.
+func EveryNth(n int64) (func(id int64, seg []byte) bool, error) {
+	if n <= 0 {
+		return nil, errors.Wrapf(ErrBadConfig, "bad thinning interval %d", n)
+	}
+	return func(id int64, seg []byte) bool {
+		return id%n == 0
+	}, nil
+}
+
+// thinWriter receives segments read by cursor and writes kept ones.
+type thinWriter struct {
+	ctx  context.Context
+	c    *Cursor
+	dst  *Buffer
+	keep func(id int64, seg []byte) bool
+}
+
+func (w thinWriter) Write(p []byte) (int, error) {
+	// cursor is advanced after write, so it still points to p
+	if !w.keep(w.c.ID(), p) {
+		return len(p), nil
+	}
+	return w.dst.WriteContext(w.ctx, p)
+}
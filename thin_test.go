@@ -0,0 +1,55 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_Thin(t *testing.T) {
+	b := New(Config{Count: 16, Segment: 1})
+	preview := New(Config{Count: 2, Segment: 1})
+	keep, err := EveryNth(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- b.Thin(ctx, preview, keep)
+	}()
+	// wait for thinning cursor
+	for b.CursorStats().Count == 0 {
+		runtime.Gosched()
+	}
+	for i := 0; i < 7; i++ {
+		if _, err := b.Write([]byte{byte(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := preview.WaitForID(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := <-done; errors.Cause(err) != context.Canceled {
+		t.Error(err, "should be", context.Canceled)
+	}
+	buf := new(bytes.Buffer)
+	if _, err := preview.ReadLast(buf, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), []byte{3, 6}) {
+		t.Error("bad thinned stream", buf.Bytes())
+	}
+	if err := b.Thin(ctx, New(Config{Count: 2, Segment: 2}), keep); errors.Cause(err) != ErrBadSegment {
+		t.Error(err, "should be", ErrBadSegment)
+	}
+	for _, n := range []int64{0, -1} {
+		if _, err := EveryNth(n); errors.Cause(err) != ErrBadConfig {
+			t.Error(err, "should be", ErrBadConfig)
+		}
+	}
+}
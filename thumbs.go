@@ -0,0 +1,87 @@
+package player
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Thumbnails is store of generated thumbnails (e.g. seek-preview images)
+// keyed by timestamp. Thumbnails are usually added from OnSegment hook by
+// external image extractor. Oldest thumbnails are evicted when count
+// exceeds maximum.
+type Thumbnails struct {
+	l      sync.RWMutex
+	max    int
+	times  []time.Time
+	images [][]byte
+}
+
+// NewThumbnails creates new store for at most max thumbnails.
+func NewThumbnails(max int) *Thumbnails {
+	return &Thumbnails{max: max}
+}
+
+// Add stores copy of thumbnail generated for timestamp at.
+func (t *Thumbnails) Add(at time.Time, img []byte) {
+	data := make([]byte, len(img))
+	copy(data, img)
+
+	t.l.Lock()
+	defer t.l.Unlock()
+	i := sort.Search(len(t.times), func(i int) bool {
+		return t.times[i].After(at)
+	})
+	t.times = append(t.times, time.Time{})
+	copy(t.times[i+1:], t.times[i:])
+	t.times[i] = at
+	t.images = append(t.images, nil)
+	copy(t.images[i+1:], t.images[i:])
+	t.images[i] = data
+	if t.max > 0 && len(t.times) > t.max {
+		k := len(t.times) - t.max
+		t.times = t.times[k:]
+		t.images = t.images[k:]
+	}
+}
+
+// Nearest returns thumbnail with timestamp nearest to at and its
+// timestamp. Returned slice must not be modified.
+func (t *Thumbnails) Nearest(at time.Time) ([]byte, time.Time, error) {
+	t.l.RLock()
+	defer t.l.RUnlock()
+	if len(t.times) == 0 {
+		return nil, time.Time{}, errors.Wrap(ErrEmpty, "no thumbnails")
+	}
+	i := sort.Search(len(t.times), func(i int) bool {
+		return !t.times[i].Before(at)
+	})
+	if i == len(t.times) || (i > 0 && at.Sub(t.times[i-1]) < t.times[i].Sub(at)) {
+		i--
+	}
+	return t.images[i], t.times[i], nil
+}
+
+// ServeHTTP writes thumbnail nearest to time from "t" query parameter
+// in RFC 3339 format, or the latest one if parameter is missing.
+func (t *Thumbnails) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	at := time.Now()
+	if v := r.URL.Query().Get("t"); v != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339Nano, v); err != nil {
+			http.Error(w, "bad time", http.StatusBadRequest)
+			return
+		}
+	}
+	img, ts, err := t.Nearest(at)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", http.DetectContentType(img))
+	w.Header().Set("Last-Modified", ts.UTC().Format(http.TimeFormat))
+	w.Write(img)
+}
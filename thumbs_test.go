@@ -0,0 +1,78 @@
+package player
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestThumbnails(t *testing.T) {
+	th := NewThumbnails(2)
+	if _, _, err := th.Nearest(time.Now()); errors.Cause(err) != ErrEmpty {
+		t.Error(err, "should be", ErrEmpty)
+	}
+	start := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	th.Add(start, []byte{0})
+	th.Add(start.Add(time.Second*2), []byte{2})
+	th.Add(start.Add(time.Second), []byte{1})
+	// first one should be evicted
+	for _, tt := range []struct {
+		at  time.Duration
+		img byte
+	}{
+		{0, 1},
+		{time.Millisecond * 1400, 1},
+		{time.Millisecond * 1600, 2},
+		{time.Second * 5, 2},
+	} {
+		img, _, err := th.Nearest(start.Add(tt.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if img[0] != tt.img {
+			t.Error("bad thumbnail", img[0], "for", tt.at, "should be", tt.img)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?t="+start.Add(time.Second).Format(time.RFC3339Nano), nil)
+	w := httptest.NewRecorder()
+	th.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), []byte{1}) {
+		t.Error("bad response", w.Code, w.Body.Bytes())
+	}
+	w = httptest.NewRecorder()
+	th.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?t=bad", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Error("bad status", w.Code)
+	}
+}
+
+func TestBuffer_OnSegment(t *testing.T) {
+	var ids []int64
+	b := New(Config{
+		Count:   4,
+		Segment: 4,
+		OnSegment: func(id int64, completed time.Time, data []byte) {
+			if completed.IsZero() || len(data) != 4 || data[0] != byte(id) {
+				t.Error("bad segment", id, completed, data)
+			}
+			ids = append(ids, id)
+		},
+	})
+	if _, err := b.Write([]byte{0, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 {
+		t.Error("partial segment should not be passed")
+	}
+	if _, err := b.Write([]byte{0, 0, 1, 1, 1, 1, 2, 2, 2, 2}); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != 0 || ids[2] != 2 {
+		t.Error("bad ids", ids)
+	}
+}
@@ -0,0 +1,32 @@
+package player
+
+import "context"
+
+// SpanInfo describes traced operation.
+type SpanInfo struct {
+	Op  string // operation name, e.g. "Write" or "ReadID"
+	Key string // stream key from Config
+	ID  int64  // segment id, -1 if operation is not bound to segment
+}
+
+// Span is traced operation.
+type Span interface {
+	// End finishes span with count of processed bytes and error.
+	End(n int, err error)
+}
+
+// Tracer starts spans for Buffer operations. It is intended to be
+// implemented as thin adapter to tracing system, e.g. OpenTelemetry,
+// setting SpanInfo fields as span attributes.
+type Tracer interface {
+	Start(ctx context.Context, info SpanInfo) Span
+}
+
+// noopTracer is default Tracer that does nothing.
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, info SpanInfo) Span {
+	return noopTracer{}
+}
+
+func (noopTracer) End(n int, err error) {}
@@ -0,0 +1,52 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type testSpan struct {
+	info SpanInfo
+	n    int
+	err  error
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, info SpanInfo) Span {
+	s := &testSpan{info: info}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (s *testSpan) End(n int, err error) {
+	s.n, s.err = n, err
+}
+
+func TestBuffer_Tracer(t *testing.T) {
+	tracer := new(testTracer)
+	b := New(Config{
+		Key:    "stream",
+		Tracer: tracer,
+	})
+	buf := make([]byte, b.SegmentSize())
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if _, err := b.ReadID(new(bytes.Buffer), 5); err == nil {
+		t.Error("should miss")
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatal("bad spans count", len(tracer.spans))
+	}
+	w, r := tracer.spans[0], tracer.spans[1]
+	if w.info.Op != "Write" || w.info.Key != "stream" || w.n != len(buf) || w.err != nil {
+		t.Errorf("bad write span %+v", w)
+	}
+	if r.info.Op != "ReadID" || r.info.ID != 5 || r.err == nil {
+		t.Errorf("bad read span %+v", r)
+	}
+}
@@ -0,0 +1,108 @@
+package player
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Transcoder converts media stream from src to renditions (e.g. ABR
+// ladder), writing rendition i to dst[i] until src ends, ctx is done or
+// transcoding fails.
+type Transcoder interface {
+	Transcode(ctx context.Context, src io.Reader, dst []io.Writer) error
+}
+
+// Transcode streams new segments of Buffer through t to rendition
+// buffers until ctx is done or t fails.
+func (b *Buffer) Transcode(ctx context.Context, t Transcoder, renditions ...*Buffer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pr, pw := io.Pipe()
+	b.l.Lock()
+	c := b.newCursor(b.nextID())
+	b.l.Unlock()
+	go func() {
+		pw.CloseWithError(b.streamTo(ctx, c, pw))
+	}()
+	dst := make([]io.Writer, len(renditions))
+	for i, r := range renditions {
+		dst[i] = r
+	}
+	err := t.Transcode(ctx, pr, dst)
+	pr.Close()
+	if err != nil {
+		return errors.Wrap(err, "failed to transcode")
+	}
+	return nil
+}
+
+// Command is Transcoder that runs external process, e.g. ffmpeg. Source
+// is written to stdin of process and rendition i is read from file
+// descriptor 3+i, so for ffmpeg outputs should be "pipe:3", "pipe:4" and
+// so on. Process is killed when ctx is done.
+type Command struct {
+	Path   string
+	Args   []string
+	Stderr io.Writer // optional
+}
+
+// Transcode runs process and waits for it to exit.
+func (c Command) Transcode(ctx context.Context, src io.Reader, dst []io.Writer) error {
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Stdin = src
+	cmd.Stderr = c.Stderr
+	readers := make([]*os.File, len(dst))
+	for i := range dst {
+		r, w, err := os.Pipe()
+		if err != nil {
+			for _, f := range cmd.ExtraFiles {
+				f.Close()
+			}
+			for _, f := range readers[:i] {
+				f.Close()
+			}
+			return errors.Wrap(err, "failed to create pipe")
+		}
+		readers[i] = r
+		cmd.ExtraFiles = append(cmd.ExtraFiles, w)
+	}
+	err := cmd.Start()
+	// write ends are inherited by process
+	for _, f := range cmd.ExtraFiles {
+		f.Close()
+	}
+	if err != nil {
+		for _, f := range readers {
+			f.Close()
+		}
+		return errors.Wrap(err, "failed to start")
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, len(dst))
+	)
+	for i, r := range readers {
+		wg.Add(1)
+		go func(i int, r *os.File) {
+			defer wg.Done()
+			defer r.Close()
+			_, errs[i] = io.Copy(dst[i], r)
+		}(i, r)
+	}
+	wg.Wait()
+	if err := cmd.Wait(); err != nil {
+		return errors.Wrap(err, "process failed")
+	}
+	for _, err := range errs {
+		if err != nil {
+			return errors.Wrap(err, "failed to copy output")
+		}
+	}
+	return nil
+}
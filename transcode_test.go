@@ -0,0 +1,67 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCommand_Transcode(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not found")
+	}
+	c := Command{Path: "sh", Args: []string{"-c", "tee /dev/fd/4 >&3"}}
+	src := make([]byte, 4096)
+	if _, err := io.ReadFull(Rand(), src); err != nil {
+		t.Fatal(err)
+	}
+	var a, b bytes.Buffer
+	if err := c.Transcode(context.Background(), bytes.NewReader(src), []io.Writer{&a, &b}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), src) || !bytes.Equal(b.Bytes(), src) {
+		t.Error("renditions should be equal to source")
+	}
+
+	c = Command{Path: "sh", Args: []string{"-c", "exit 1"}}
+	if err := c.Transcode(context.Background(), bytes.NewReader(src), nil); err == nil {
+		t.Error("should fail")
+	}
+}
+
+func TestBuffer_Transcode(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not found")
+	}
+	src := New(Config{Count: 4, Segment: 512})
+	dst := New(Config{Count: 4, Segment: 512})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- src.Transcode(ctx, Command{Path: "sh", Args: []string{"-c", "cat >&3"}}, dst)
+	}()
+
+	seg := bytes.Repeat([]byte{1}, 512)
+	buf := make([]byte, 512)
+	deadline := time.After(time.Second * 5)
+	for dst.Get(buf, 0) != nil {
+		if _, err := src.Write(seg); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-deadline:
+			t.Fatal("segment should be transcoded")
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	if !bytes.Equal(buf, seg) {
+		t.Error("bad segment")
+	}
+	cancel()
+	if err := <-done; err == nil {
+		t.Error("should be cancelled")
+	}
+}
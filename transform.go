@@ -0,0 +1,34 @@
+package player
+
+// Transform modifies data on write and read paths of Buffer, e.g.
+// encryption or byte scrambling.
+type Transform interface {
+	// Write transforms data before it is appended to Buffer.
+	Write(p []byte) ([]byte, error)
+	// Read transforms copy of segment with provided id before it is
+	// written to reader's io.Writer. It may modify p.
+	Read(id int64, p []byte) ([]byte, error)
+}
+
+// transformWrite applies write path of all transforms in order.
+func (b *Buffer) transformWrite(p []byte) ([]byte, error) {
+	var err error
+	for _, t := range b.transforms {
+		if p, err = t.Write(p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// transformRead applies read path of all transforms in reverse order,
+// so read path of first transform is applied last.
+func (b *Buffer) transformRead(id int64, p []byte) ([]byte, error) {
+	var err error
+	for i := len(b.transforms) - 1; i >= 0; i-- {
+		if p, err = b.transforms[i].Read(id, p); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,65 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// xorTransform scrambles data on write and restores it on read.
+type xorTransform byte
+
+func (x xorTransform) xor(p []byte) []byte {
+	out := make([]byte, len(p))
+	for i := range p {
+		out[i] = p[i] ^ byte(x)
+	}
+	return out
+}
+
+func (x xorTransform) Write(p []byte) ([]byte, error) {
+	return x.xor(p), nil
+}
+
+func (x xorTransform) Read(id int64, p []byte) ([]byte, error) {
+	return x.xor(p), nil
+}
+
+// headerTransform prepends header to delivered segments.
+type headerTransform string
+
+func (h headerTransform) Write(p []byte) ([]byte, error) {
+	return p, nil
+}
+
+func (h headerTransform) Read(id int64, p []byte) ([]byte, error) {
+	return append([]byte(h), p...), nil
+}
+
+func TestBuffer_Transforms(t *testing.T) {
+	b := New(Config{
+		Transforms: []Transform{headerTransform("hdr"), xorTransform(0x55)},
+	})
+	data := new(bytes.Buffer)
+	n, err := io.CopyN(io.MultiWriter(b, data), Rand(), b.SegmentSize()*2)
+	if err != nil || n != b.SegmentSize()*2 {
+		t.Error(err, n)
+	}
+	stored := make([]byte, b.SegmentSize())
+	if err := b.Get(stored, 0); err != nil {
+		t.Error(err)
+	}
+	if bytes.Equal(stored, data.Bytes()[:b.SegmentSize()]) {
+		t.Error("stored data should be scrambled")
+	}
+	c := b.NewCursor(0)
+	defer c.Close()
+	out := new(bytes.Buffer)
+	if _, err := c.ReadNext(out); err != nil {
+		t.Error(err)
+	}
+	expected := append([]byte("hdr"), data.Bytes()[:b.SegmentSize()]...)
+	if !bytes.Equal(out.Bytes(), expected) {
+		t.Error("delivered data should be restored with header")
+	}
+}
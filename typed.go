@@ -0,0 +1,86 @@
+package player
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// Possible errors of TypedBuffer.
+const (
+	// ErrTooLargeRecord indicates that encoded record does not fit segment.
+	ErrTooLargeRecord Error = "record is too large"
+	// ErrBadRecord indicates that segment does not contain valid record.
+	ErrBadRecord Error = "bad record"
+)
+
+// recordHeader is size of record length prefix.
+const recordHeader = 4
+
+// Codec encodes and decodes records of TypedBuffer.
+type Codec[T any] interface {
+	Marshal(v T) ([]byte, error)
+	Unmarshal(data []byte, v *T) error
+}
+
+// TypedBuffer buffers structured records, one record per segment, with
+// windowing, cursors and persistence of underlying Buffer. Encoded record
+// must fit segment size minus 4 bytes of length prefix.
+type TypedBuffer[T any] struct {
+	b     *Buffer
+	codec Codec[T]
+}
+
+// NewTyped creates TypedBuffer on top of b. TypedBuffer should be
+// the only writer of b, so records stay aligned to segments.
+func NewTyped[T any](b *Buffer, codec Codec[T]) *TypedBuffer[T] {
+	return &TypedBuffer[T]{
+		b:     b,
+		codec: codec,
+	}
+}
+
+// Buffer returns underlying Buffer.
+func (t *TypedBuffer[T]) Buffer() *Buffer {
+	return t.b
+}
+
+// Write appends record as new segment.
+func (t *TypedBuffer[T]) Write(v T) error {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+	p := t.b.GetBuffer()
+	defer t.b.PutBuffer(p)
+	seg := *p
+	if len(data) > len(seg)-recordHeader {
+		return errors.Wrap(ErrTooLargeRecord, "failed to write")
+	}
+	binary.BigEndian.PutUint32(seg, uint32(len(data)))
+	n := copy(seg[recordHeader:], data)
+	for i := recordHeader + n; i < len(seg); i++ {
+		seg[i] = 0
+	}
+	_, err = t.b.Write(seg)
+	return err
+}
+
+// Get returns record with provided id.
+func (t *TypedBuffer[T]) Get(id int64) (T, error) {
+	var v T
+	p := t.b.GetBuffer()
+	defer t.b.PutBuffer(p)
+	seg := *p
+	if err := t.b.Get(seg, id); err != nil {
+		return v, err
+	}
+	n := int(binary.BigEndian.Uint32(seg))
+	if n > len(seg)-recordHeader {
+		return v, errors.Wrap(ErrBadRecord, "bad length")
+	}
+	if err := t.codec.Unmarshal(seg[recordHeader:recordHeader+n], &v); err != nil {
+		return v, errors.Wrap(err, "failed to unmarshal")
+	}
+	return v, nil
+}
@@ -0,0 +1,52 @@
+package player
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type jsonCodec[T any] struct{}
+
+func (jsonCodec[T]) Marshal(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec[T]) Unmarshal(data []byte, v *T) error {
+	return json.Unmarshal(data, v)
+}
+
+type frame struct {
+	Sensor string
+	Value  float64
+}
+
+func TestTypedBuffer(t *testing.T) {
+	tb := NewTyped[frame](New(Config{
+		Count:   2,
+		Segment: 64,
+	}), jsonCodec[frame]{})
+	for i := 0; i < 3; i++ {
+		if err := tb.Write(frame{Sensor: "t", Value: float64(i)}); err != nil {
+			t.Error(err)
+		}
+	}
+	if tb.Buffer().FirstID() != 1 || tb.Buffer().LastID() != 2 {
+		t.Error("bad window", tb.Buffer().FirstID(), tb.Buffer().LastID())
+	}
+	f, err := tb.Get(2)
+	if err != nil {
+		t.Error(err)
+	}
+	if f.Sensor != "t" || f.Value != 2 {
+		t.Errorf("bad frame %+v", f)
+	}
+	if _, err := tb.Get(0); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+	if err := tb.Write(frame{Sensor: strings.Repeat("t", 64)}); errors.Cause(err) != ErrTooLargeRecord {
+		t.Error(err, "should be", ErrTooLargeRecord)
+	}
+}
@@ -0,0 +1,35 @@
+package player
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"github.com/pkg/errors"
+)
+
+// WriteSegmentTo writes segment with provided id to w without copying it
+// into intermediate buffer. Segment data is never modified after it is
+// written, so it is safe to send it without holding the lock. For
+// net.Conn, net.Buffers uses writev where supported. If Buffer has
+// transforms, segment is copied and transformed as in ReadID.
+func (b *Buffer) WriteSegmentTo(w io.Writer, id int64) (n int64, err error) {
+	if len(b.transforms) > 0 {
+		// transforms are set only by New
+		n, err := b.ReadID(w, id) // traced by ReadID
+		return int64(n), err
+	}
+	span := b.tracer.Start(context.Background(), SpanInfo{Op: "WriteSegmentTo", Key: b.key, ID: id})
+	defer func() {
+		span.End(int(n), err)
+	}()
+
+	b.l.RLock()
+	if err := b.acquireID(id); err != nil {
+		b.l.RUnlock()
+		return 0, errors.Wrap(err, "bad id")
+	}
+	bufs := net.Buffers{b.getSegment(id)}
+	b.l.RUnlock()
+	return bufs.WriteTo(w)
+}
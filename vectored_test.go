@@ -0,0 +1,51 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestBuffer_WriteSegmentTo(t *testing.T) {
+	b := NewDefault()
+	data := new(bytes.Buffer)
+	if _, err := io.CopyN(io.MultiWriter(b, data), Rand(), b.SegmentSize()*3); err != nil {
+		t.Error(err)
+	}
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		if _, err := b.WriteSegmentTo(server, 2); err != nil {
+			t.Error(err)
+		}
+	}()
+	got, err := io.ReadAll(client)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(got, data.Bytes()[b.SegmentSize()*2:]) {
+		t.Error("data mismatch")
+	}
+	if _, err := b.WriteSegmentTo(new(bytes.Buffer), 5); errors.Cause(err) != ErrMiss {
+		t.Error(err, "should be", ErrMiss)
+	}
+}
+
+func BenchmarkBuffer_WriteSegmentTo(b *testing.B) {
+	buf := NewDefault()
+	if _, err := io.CopyN(buf, Rand(), buf.SegmentSize()*4); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(buf.SegmentSize())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := buf.WriteSegmentTo(io.Discard, 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
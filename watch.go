@@ -0,0 +1,138 @@
+package player
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Watch flags Buffer as stalled when no segment is completed within
+// timeout (usually a few segment durations), calling OnStall hook. Flag is
+// reset by next write, which calls OnResume hook. If Slate is set, Watch
+// injects slate segments every segment duration (or timeout, if duration
+// is unknown) while ingest is stalled. Watch blocks until ctx is done.
+func (b *Buffer) Watch(ctx context.Context, timeout time.Duration) error {
+	start := b.clock.Now()
+	for {
+		b.l.Lock()
+		last, stalled := b.completed, b.stalled
+		interval := b.duration
+		if interval <= 0 {
+			interval = timeout
+		}
+		if last.Before(start) {
+			last = start
+		}
+		changed := b.changed()
+		b.l.Unlock()
+
+		var timer Timer
+		var expired <-chan time.Time
+		switch {
+		case !stalled:
+			timer = b.clock.NewTimer(last.Add(timeout).Sub(b.clock.Now()))
+		case b.slate != nil:
+			timer = b.clock.NewTimer(last.Add(interval).Sub(b.clock.Now()))
+		}
+		if timer != nil {
+			expired = timer.C()
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return errors.Wrap(ctx.Err(), "watch stopped")
+		case <-changed:
+			if timer != nil {
+				timer.Stop()
+			}
+			continue
+		case <-expired:
+		}
+
+		if stalled {
+			b.injectSlate(ctx, last)
+			continue
+		}
+		b.l.Lock()
+		stall := !b.stalled && !b.completed.After(last)
+		if stall {
+			b.stalled = true
+		}
+		next := b.nextID()
+		b.l.Unlock()
+		if stall {
+			b.log.Printf("player: %s: ingest stalled since %s", b.key, last)
+			if b.stallHook != nil {
+				b.stallHook(last)
+			}
+			b.emit(Event{Kind: EventGapDetected, ID: next, Time: last})
+		}
+	}
+}
+
+// StaticSlate returns Slate that always injects provided segment.
+func StaticSlate(seg []byte) func(id int64) []byte {
+	return func(id int64) []byte {
+		return seg
+	}
+}
+
+// injectSlate writes slate segment if ingest is still stalled since last.
+// First slate segment of stall is marked as discontinuity, as well as
+// first segment of resumed ingest. Trailing partial segment of stalled
+// ingest is dropped. Slate is written as ingest, with Transforms and
+// backpressure.
+func (b *Buffer) injectSlate(ctx context.Context, last time.Time) {
+	b.sl.Lock()
+	b.l.Lock()
+	if !b.stalled || b.closed || b.completed.After(last) {
+		b.l.Unlock()
+		b.sl.Unlock()
+		return
+	}
+	if partial := b.dropPartial(); partial > 0 {
+		b.log.Printf("player: %s: dropped %d bytes of partial segment for slate", b.key, partial)
+	}
+	id := b.nextID()
+	b.l.Unlock()
+	b.sl.Unlock()
+	seg := b.slate(id)
+	if int64(len(seg)) != b.SegmentSize() {
+		b.log.Printf("player: %s: bad slate size %d", b.key, len(seg))
+		return
+	}
+	if _, err := b.writeAs(ctx, id, seg, true); err != nil {
+		b.log.Printf("player: %s: failed to inject slate: %v", b.key, err)
+	}
+}
+
+// Stalled reports whether ingest is stalled, as detected by Watch.
+func (b *Buffer) Stalled() bool {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	return b.stalled
+}
+
+// addDiscontinuity marks segment with provided id as discontinuity.
+// No locks.
+func (b *Buffer) addDiscontinuity(id int64) {
+	if b.disc == nil {
+		b.disc = make(map[int64]struct{})
+	}
+	b.disc[id] = struct{}{}
+}
+
+// Discontinuity reports whether segment with provided id starts
+// discontinuity, e.g. after ingest stall.
+func (b *Buffer) Discontinuity(id int64) (bool, error) {
+	b.l.RLock()
+	defer b.l.RUnlock()
+	if err := b.acquireID(id); err != nil {
+		return false, errors.Wrap(err, "bad id")
+	}
+	_, ok := b.disc[id]
+	return ok, nil
+}
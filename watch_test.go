@@ -0,0 +1,129 @@
+package player
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuffer_Watch(t *testing.T) {
+	stalls := make(chan time.Time, 1)
+	resumes := make(chan time.Duration, 1)
+	b := New(Config{
+		Count:             4,
+		Segment:           512,
+		MarkDiscontinuity: true,
+		OnStall: func(last time.Time) {
+			stalls <- last
+		},
+		OnResume: func(stalled time.Duration) {
+			resumes <- stalled
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, time.Millisecond*20)
+
+	buf := make([]byte, 512)
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	select {
+	case <-stalls:
+	case <-time.After(time.Second):
+		t.Fatal("stall should be detected")
+	}
+	if !b.Stalled() {
+		t.Error("should be stalled")
+	}
+	if _, err := b.Write(buf); err != nil {
+		t.Error(err)
+	}
+	if d := <-resumes; d < time.Millisecond*20 {
+		t.Error("bad stall duration", d)
+	}
+	if b.Stalled() {
+		t.Error("should be resumed")
+	}
+	if disc, err := b.Discontinuity(1); err != nil || !disc {
+		t.Error("segment after stall should be discontinuity", err)
+	}
+	if disc, err := b.Discontinuity(0); err != nil || disc {
+		t.Error("first segment should not be discontinuity", err)
+	}
+}
+
+func TestBuffer_Slate(t *testing.T) {
+	slate := bytes.Repeat([]byte{0x47}, 512)
+	b := New(Config{
+		Count:    8,
+		Segment:  512,
+		Duration: time.Millisecond * 5,
+		Slate:    StaticSlate(slate),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, time.Millisecond*20)
+
+	buf := make([]byte, 512)
+	if _, err := b.Write(make([]byte, 512+100)); err != nil {
+		t.Error(err)
+	}
+	for b.LastID() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	if !b.Stalled() {
+		t.Error("should be stalled")
+	}
+	if err := b.Get(buf, 1); err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(buf, slate) {
+		t.Error("slate should be injected")
+	}
+	if disc, err := b.Discontinuity(1); err != nil || !disc {
+		t.Error("first slate segment should be discontinuity", err)
+	}
+	if disc, err := b.Discontinuity(2); err != nil || disc {
+		t.Error("second slate segment should not be discontinuity", err)
+	}
+
+	// resuming ingest
+	if _, err := b.Write(make([]byte, 512)); err != nil {
+		t.Error(err)
+	}
+	if disc, err := b.Discontinuity(b.LastID()); err != nil || !disc {
+		t.Error("resumed segment should be discontinuity", err)
+	}
+}
+
+func TestBuffer_SlateTransform(t *testing.T) {
+	b := New(Config{
+		Count:      8,
+		Segment:    4,
+		Duration:   time.Millisecond * 5,
+		Slate:      StaticSlate([]byte("SSSS")),
+		Transforms: []Transform{xorTransform(0xFF)},
+	})
+	tee := new(bytes.Buffer)
+	remove := b.TeeTo(tee, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Watch(ctx, time.Millisecond*20)
+	if err := b.WaitForID(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	remove()
+	buf := new(bytes.Buffer)
+	if _, err := b.ReadID(buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "SSSS" {
+		t.Errorf("slate should be transformed, got %q", buf.String())
+	}
+	if tee.Len() == 0 {
+		t.Error("tee should receive slate")
+	}
+}